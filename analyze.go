@@ -0,0 +1,184 @@
+package prompt
+
+import (
+	"strings"
+)
+
+// kinds of the word under the cursor, see inputContext, and of the args, see argWalker
+const (
+	inputFlagName = iota
+	inputFlagValue
+	inputPositional
+	inputSeparator // "--", the args behind it are all positional
+)
+
+// inputContext describes what the user is typing in the cmd of a handler
+type inputContext struct {
+	Kind int
+
+	// Suggest is the index of the suggest the current word belongs to, the flag whose
+	// value is typed or the positional param of the slot. -1 if unknown.
+	Suggest int
+	// Slot is the index of the positional arg under the cursor, only for inputPositional
+	Slot int
+
+	Prefix string // text of the current word before the value, e.g. "-name=" of an inline value
+	Value  string // value or flag name typed so far
+
+	Supplied map[int]bool // index of the suggests already supplied before the current word
+}
+
+// isFlagArg reports whether arg is parsed as flag, like flag.FlagSet does
+func isFlagArg(arg string) bool {
+	return len(arg) > 1 && arg[0] == '-' && arg != "--"
+}
+
+// flagName returns the name of the flag arg and its inline value after "=" if any
+func flagName(arg string) (name, value string, hasValue bool) {
+	name = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	if index := strings.Index(name, "="); index >= 0 {
+		return name[:index], name[index+1:], true
+	}
+	return name, "", false
+}
+
+// lookupSuggest returns the index of the suggest whose name or short name is name
+func (h *HandlerInfo) lookupSuggest(name string) int {
+	for index, s := range h.Suggests {
+		if s.Positional {
+			continue
+		}
+		if s.Text == name || (s.Short != "" && s.Short == name) {
+			return index
+		}
+		for _, alias := range s.Aliases {
+			if alias == name {
+				return index
+			}
+		}
+	}
+	return -1
+}
+
+// isBoolParam reports whether the suggest at index is bound to a bool param
+func (h *HandlerInfo) isBoolParam(index int) bool {
+	if index < 0 || index >= len(h.Suggests) {
+		return false
+	}
+	converter, ok := h.paramConverter(h.Suggests[index].Text)
+	return ok && isBoolType(converter.Type)
+}
+
+// positionalSuggests returns the index of the positional suggests in order
+func (h *HandlerInfo) positionalSuggests() []int {
+	indexes := []int{}
+	for index, s := range h.Suggests {
+		if s.Positional {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// walkedArg is an arg of a cmd classified by argWalker
+type walkedArg struct {
+	Kind     int    // inputFlagName, inputFlagValue, inputPositional or inputSeparator
+	Suggest  int    // the flag, the flag of the value or the positional param, -1 if unknown
+	Value    string // the value or positional arg, the inline value of a flag like -n=5
+	HasValue bool   // the flag has an inline value
+	Supplied []int  // the suggests set by the flag, several for a GNU group like -vq
+	Err      error  // the GNU group has an unknown short flag
+}
+
+// argWalker walks the args of a cmd of h the way they are parsed, see parseArgs: flags
+// with their values, GNU groups of short flags, "--" and positional args in order
+type argWalker struct {
+	h              *HandlerInfo
+	positionals    []int
+	slot           int  // index of the next positional arg
+	onlyPositional bool // after "--"
+	expectValue    int  // the suggest whose value is the next arg, -1 if none
+}
+
+func (h *HandlerInfo) newArgWalker() *argWalker {
+	return &argWalker{h: h, positionals: h.positionalSuggests(), expectValue: -1}
+}
+
+// next classifies arg and moves past it
+func (w *argWalker) next(arg string) walkedArg {
+	h := w.h
+	switch {
+	case w.expectValue >= 0:
+		walked := walkedArg{Kind: inputFlagValue, Suggest: w.expectValue, Value: arg}
+		w.expectValue = -1
+		return walked
+	case !w.onlyPositional && arg == "--":
+		w.onlyPositional = true
+		return walkedArg{Kind: inputSeparator, Suggest: -1}
+	case !w.onlyPositional && h.GNUFlags && isGNUShortArg(arg):
+		expanded, supplied, expectValue, err := h.expandShortArg(arg)
+		walked := walkedArg{Kind: inputFlagName, Suggest: -1, Supplied: supplied, Err: err}
+		if err == nil {
+			// the last short flag of the group may have an inline value, -n5 is -n=5
+			name, value, hasValue := flagName(expanded[len(expanded)-1])
+			walked.Suggest, walked.Value, walked.HasValue = h.lookupSuggest(name), value, hasValue
+		}
+		w.expectValue = expectValue
+		return walked
+	case !w.onlyPositional && isFlagArg(arg):
+		name, value, hasValue := flagName(arg)
+		walked := walkedArg{Kind: inputFlagName, Suggest: h.lookupSuggest(name), Value: value, HasValue: hasValue}
+		if walked.Suggest >= 0 {
+			walked.Supplied = []int{walked.Suggest}
+			if !hasValue && !h.isBoolParam(walked.Suggest) {
+				w.expectValue = walked.Suggest
+			}
+		}
+		return walked
+	default:
+		walked := walkedArg{Kind: inputPositional, Suggest: -1, Value: arg}
+		if w.slot < len(w.positionals) {
+			walked.Suggest = w.positionals[w.slot]
+			walked.Supplied = []int{walked.Suggest}
+		}
+		w.slot++
+		return walked
+	}
+}
+
+// analyzeInputs works out what the last of inputs is, inputs[0] is the handler name and
+// the last one is the word under the cursor, maybe empty.
+func (h *HandlerInfo) analyzeInputs(inputs []string) inputContext {
+	inputCtx := inputContext{Suggest: -1, Supplied: map[int]bool{}}
+	w := h.newArgWalker()
+	for _, input := range inputs[1 : len(inputs)-1] {
+		for _, s := range w.next(input).Supplied {
+			inputCtx.Supplied[s] = true
+		}
+	}
+
+	// the word under the cursor may be incomplete, e.g. "-" on the way to a flag
+	current := inputs[len(inputs)-1]
+	inputCtx.Value = current
+	switch {
+	case w.expectValue >= 0:
+		inputCtx.Kind = inputFlagValue
+		inputCtx.Suggest = w.expectValue
+	case !w.onlyPositional && (isFlagArg(current) || current == "-"):
+		name, value, hasValue := flagName(current)
+		inputCtx.Kind = inputFlagName
+		if hasValue {
+			inputCtx.Kind = inputFlagValue
+			inputCtx.Suggest = h.lookupSuggest(name)
+			inputCtx.Prefix = current[:len(current)-len(value)]
+			inputCtx.Value = value
+		}
+	default:
+		inputCtx.Kind = inputPositional
+		inputCtx.Slot = w.slot
+		if w.slot < len(w.positionals) {
+			inputCtx.Suggest = w.positionals[w.slot]
+		}
+	}
+	return inputCtx
+}
@@ -0,0 +1,66 @@
+package prompt
+
+import "strings"
+
+// completeCommonPrefix completes the word before the cursor like bash does: a single
+// suggest starting with the word is inserted fully, otherwise the longest common prefix
+// of the suggests starting with it. The line is kept if nothing can be inserted, e.g. the
+// suggests only match fuzzily. The next tab on the completed or kept line cycles the
+// suggests.
+func (m *PromptModel) completeCommonPrefix() {
+	buffer := m.historyBuffers[m.historyIndex]
+	input := buffer[:m.historyBufferPos]
+	m.completedLine = buffer
+	word := typedWord(input)
+	if !strings.HasSuffix(input, word) || (len(buffer) > len(input) && buffer[len(input)] != ' ') {
+		// the word is quoted or the cursor is inside of it
+		return
+	}
+	candidates := []Suggest{}
+	for _, s := range m.matchSuggests {
+		if !isHintSuggest(s) && strings.HasPrefix(s.Text, word) {
+			candidates = append(candidates, s)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	text, partial := candidates[0].Text, candidates[0].Partial
+	if len(candidates) > 1 {
+		text, partial = commonPrefix(candidates), true
+		if len(text) == len(word) {
+			return
+		}
+	}
+	if candidates[0].SuggestType == SuggestOfValue {
+		quoted := QuoteArg(text)
+		if len(candidates) > 1 && quoted != text {
+			// a quoted prefix would close the quote
+			return
+		}
+		text = quoted
+	}
+	newCmd, newPos := replaceScope(buffer, text, m.historyBufferPos)
+	m.textInput.SetValue(newCmd)
+	m.textInput.SetCursor(newPos)
+	m.historyBuffers[m.historyIndex] = newCmd
+	m.historyBufferPos = m.textInput.Position()
+	if !partial {
+		// completed, the next tab cycles
+		m.completedLine = newCmd
+	}
+}
+
+// commonPrefix returns the longest common prefix of the text of suggests, it never ends
+// inside of a multi-byte char
+func commonPrefix(suggests []Suggest) string {
+	prefix := suggests[0].Text
+	for _, s := range suggests[1:] {
+		end := 0
+		for end < len(prefix) && end < len(s.Text) && prefix[end] == s.Text[end] {
+			end++
+		}
+		prefix = prefix[:end]
+	}
+	return strings.ToValidUTF8(prefix, "")
+}
@@ -0,0 +1,61 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		texts []string
+		want  string
+	}{
+		{[]string{"status"}, "status"},
+		{[]string{"status", "start", "stash"}, "sta"},
+		{[]string{"start", "start-all"}, "start"},
+		{[]string{"a", "b"}, ""},
+		{[]string{"", "a"}, ""},
+		// é and è share their first byte
+		{[]string{"café", "cafè"}, "caf"},
+		{[]string{"日本", "日曜"}, "日"},
+	}
+	for _, tt := range tests {
+		suggests := []Suggest{}
+		for _, text := range tt.texts {
+			suggests = append(suggests, Suggest{Text: text})
+		}
+		if got := commonPrefix(suggests); got != tt.want {
+			t.Errorf("commonPrefix(%q) = %q, want %q", tt.texts, got, tt.want)
+		}
+	}
+}
+
+func TestCompleteCommonPrefix(t *testing.T) {
+	tests := []struct {
+		typed string
+		tabs  []string // the line after every tab
+	}{
+		{"s", []string{"st", "st", "start"}},
+		// nothing to insert, the first tab doesn't cycle
+		{"st", []string{"st", "start", "status"}},
+		{"sp", []string{"sp", "stop"}},
+		{"sto", []string{"stop", "stop"}},
+	}
+	for _, tt := range tests {
+		m := newTestModel(t, WithCommonPrefixCompletion(true))
+		for _, name := range []string{"start", "status", "stop"} {
+			m.RegisterHandler(func() {}, name)
+		}
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.typed)})
+		got := []string{}
+		for range tt.tabs {
+			m.Update(tea.KeyMsg{Type: tea.KeyTab})
+			got = append(got, m.textInput.Value())
+		}
+		if !reflect.DeepEqual(got, tt.tabs) {
+			t.Errorf("tabs after %q = %q, want %q", tt.typed, got, tt.tabs)
+		}
+	}
+}
@@ -18,6 +18,33 @@ var (
 				Italic(true).
 				Background(lipgloss.Color("#9EA9AEFF"))
 
+	defaultErrorStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FF5F5F"))
+
+	defaultMatchStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FFD700"))
+
+	defaultGhostStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("241"))
+
+	defaultInvalidStyle = lipgloss.NewStyle().
+				Underline(true).
+				Foreground(lipgloss.Color("#FF5F5F"))
+
+	defaultSignatureStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("245"))
+
+	defaultSignatureCurrentStyle = lipgloss.NewStyle().
+					Bold(true).
+					Underline(true).
+					Foreground(lipgloss.Color("#00B3FF"))
+
+	defaultSignatureSuppliedStyle = lipgloss.NewStyle().
+					Faint(true).
+					Foreground(lipgloss.Color("239"))
+
 	defaultRunCmdDeply int64 = 20
 
 	defaultPrintCmd      bool   = true
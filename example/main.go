@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"reflect"
+	"time"
 
 	"github.com/lureiny/go-prompt"
 )
@@ -42,6 +45,37 @@ func main() {
 			{Text: "d", Description: "every thing", Default: true},
 		}))
 
+	m.RegisterHandler(nil, "user",
+		prompt.WithSubHandlers(
+			prompt.NewHandlerInfo("add", userAdd,
+				prompt.WithSuggests([]prompt.Suggest{
+					{Text: "name", Description: "user name", Required: true, Pattern: "[a-z][a-z0-9_]*"},
+				}),
+				prompt.WithHandlerHelpMsg("add user")),
+			prompt.NewHandlerInfo("del", userDel,
+				prompt.WithSuggests([]prompt.Suggest{
+					{Text: "name", Description: "user name"},
+					{Text: "force", Description: "delete without check"},
+				}),
+				prompt.WithHandlerHelpMsg("delete user")),
+		),
+		prompt.WithHandlerHelpMsg("manage users"),
+	)
+
+	m.RegisterHandler(sleep, "sleep",
+		prompt.WithSuggests([]prompt.Suggest{
+			{Text: "seconds", Default: 3, Description: "seconds to sleep", Min: 0, Max: 60},
+		}),
+		prompt.WithHandlerHelpMsg("sleep some seconds, ctrl+c to cancel"))
+
+	m.RegisterHandler(deploy, "deploy", prompt.WithHandlerHelpMsg("deploy to env"))
+
+	m.RegisterHandler(load, "load",
+		prompt.WithSuggests([]prompt.Suggest{
+			{Text: "file", Description: "file to load", Required: true},
+		}),
+		prompt.WithHandlerHelpMsg("load a file, tab completes the path"))
+
 	m.RegisterHandler(prompt.DefaultExitFunc, "exit", prompt.WithExitAfterRun(true))
 
 	if err := m.Run(); err != nil {
@@ -82,3 +116,40 @@ func calc(a, b int16) string {
 func boolTest(b bool, name string, c, d bool) {
 	fmt.Println(b, name, c, d)
 }
+
+func userAdd(name string) {
+	fmt.Println("add user", name)
+}
+
+func userDel(name string, force bool) {
+	fmt.Println("delete user", name, force)
+}
+
+func sleep(ctx context.Context, seconds int) {
+	select {
+	case <-ctx.Done():
+		fmt.Println("sleep canceled")
+	case <-time.After(time.Duration(seconds) * time.Second):
+		fmt.Println("sleep done")
+	}
+}
+
+type DeployOptions struct {
+	Env      string   `flag:"env" short:"e" default:"dev" desc:"target env" enum:"dev,test,prod"`
+	Replicas int      `default:"2" desc:"replica num" min:"1" max:"10"`
+	Force    bool     `desc:"deploy without check"`
+	Tags     []string `flag:"tag" desc:"image tags"`
+}
+
+func deploy(opts DeployOptions) {
+	fmt.Printf("deploy %+v\n", opts)
+}
+
+func load(file prompt.Path) error {
+	info, err := os.Stat(string(file))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("load %s, %d bytes\n", file, info.Size())
+	return nil
+}
@@ -0,0 +1,116 @@
+package prompt
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	frecencyHalfLife = 7 * 24 * time.Hour // the weight of a use halves every week
+	maxFrecencyBonus = scorePrefix        // frecency never outweighs more than a matched prefix
+)
+
+// historyUse is a cmd line run at some time, loaded from the history file or run now
+type historyUse struct {
+	cmd string
+	at  time.Time
+}
+
+// recordUse adds a cmd line to the uses ranking the suggests
+func (m *PromptModel) recordUse(cmd string, at time.Time) {
+	use := historyUse{cmd: cmd, at: at}
+	m.historyUses = append(m.historyUses, use)
+	if m.frecencyScores != nil {
+		m.scoreUse(use, time.Now())
+	}
+	if m.paramHistory != nil {
+		m.collectValues(use)
+	}
+}
+
+// frecencyScore returns the frecency of key, see frecencyKey. The scores are built from
+// the uses when needed, after the handlers are registered.
+func (m *PromptModel) frecencyScore(key string) float64 {
+	if m.frecencyScores == nil {
+		m.frecencyScores = map[string]float64{}
+		now := time.Now()
+		for _, use := range m.historyUses {
+			m.scoreUse(use, now)
+		}
+	}
+	return m.frecencyScores[key]
+}
+
+// scoreUse adds the weight of use to the handlers and flags named in its cmd line, a
+// recent use weighs up to 1 and the weight decays with frecencyHalfLife
+func (m *PromptModel) scoreUse(use historyUse, now time.Time) {
+	weight := math.Pow(0.5, float64(now.Sub(use.at))/float64(frecencyHalfLife))
+	tokens, _ := tokenize(use.cmd)
+	segments, err := splitCommandChain(tokens)
+	if err != nil {
+		return
+	}
+	for _, segment := range segments {
+		words := tokenValues(segment.Tokens)
+		handler, depth := m.resolveHandler(words)
+		if handler == nil {
+			continue
+		}
+		for named := handler; named != nil; named = named.parent {
+			m.frecencyScores[frecencyKey(named.parent, named.Name)] += weight
+		}
+		for _, index := range handler.usedFlags(words[depth+1:]) {
+			m.frecencyScores[frecencyKey(handler, "-"+handler.Suggests[index].Text)] += weight
+		}
+	}
+}
+
+// usedFlags returns the index of the suggests set as flag in args
+func (h *HandlerInfo) usedFlags(args []string) []int {
+	used := []int{}
+	w := h.newArgWalker()
+	for _, arg := range args {
+		if walked := w.next(arg); walked.Kind == inputFlagName {
+			used = append(used, walked.Supplied...)
+		}
+	}
+	return used
+}
+
+// frecencyKey returns the key of the sub handler or flag name of h in the frecency
+// scores, h is nil for the top handlers. Flag names start with "-".
+func frecencyKey(h *HandlerInfo, name string) string {
+	if h == nil {
+		return name
+	}
+	return h.FullName() + " " + name
+}
+
+// frecencyBonus returns the func scoring the suggests of h by frecency, h is nil for the
+// top handlers. The bonus is added to the score of the fuzzy match.
+func (m *PromptModel) frecencyBonus(h *HandlerInfo) func(s Suggest) int {
+	return func(s Suggest) int {
+		if !m.frecencyRanking {
+			return 0
+		}
+		key := ""
+		switch s.SuggestType {
+		case SuggestOfHandler:
+			key = frecencyKey(h, s.Text)
+		case SuggestOfParam:
+			if h == nil {
+				return 0
+			}
+			name := strings.TrimPrefix(strings.TrimPrefix(s.Text, h.SuggestPrefix), gnuShortPrefix)
+			index := h.lookupSuggest(name)
+			if index < 0 {
+				return 0
+			}
+			key = frecencyKey(h, "-"+h.Suggests[index].Text)
+		default:
+			return 0
+		}
+		return min(int(2*math.Log2(1+m.frecencyScore(key))), maxFrecencyBonus)
+	}
+}
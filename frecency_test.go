@@ -0,0 +1,49 @@
+package prompt
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFrecencyScore(t *testing.T) {
+	m := newTestModel(t)
+	m.RegisterHandler(nil, "git", WithSubHandlers(
+		NewHandlerInfo("status", func(verbose bool) {}, WithSuggests([]Suggest{{Text: "verbose", Short: "v"}})),
+		NewHandlerInfo("stash", func() {}),
+	))
+	now := time.Now()
+	m.recordUse("git status -v", now)
+	m.recordUse("git status --verbose; git stash", now.Add(-frecencyHalfLife))
+	m.recordUse("nope status", now)
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"git", 2}, // every command of a chain is a use
+		{"git status", 1.5},
+		{"git stash", 0.5},
+		{"git status -verbose", 1.5},
+		{"nope", 0},
+	}
+	for _, tt := range tests {
+		if got := m.frecencyScore(tt.key); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("frecencyScore(%q) = %.2f, want %.2f", tt.key, got, tt.want)
+		}
+	}
+
+	// status is used more and ranks above stash with the same fuzzy score
+	for _, enable := range []bool{true, false} {
+		m.frecencyRanking = enable
+		m.textInput.SetValue("git st")
+		m.historyBuffers[m.historyIndex], m.historyBufferPos = "git st", 6
+		m.updateSuggentList()
+		want := []string{"status", "stash"}
+		if !enable {
+			want = []string{"stash", "status"}
+		}
+		if got := suggestTexts(m.matchSuggests); len(got) != 2 || got[0] != want[0] {
+			t.Errorf("suggests = %q with frecency ranking %v, want %q", got, enable, want)
+		}
+	}
+}
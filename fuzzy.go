@@ -0,0 +1,127 @@
+package prompt
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// scores of FuzzyMatch
+const (
+	scoreMatch       = 1  // every matched char
+	scorePrefix      = 8  // the first char of text is matched
+	scoreBoundary    = 4  // a char at a word boundary is matched, e.g. "s" of "git-status"
+	scoreConsecutive = 3  // a char right behind the last matched one is matched
+	scoreGap         = -1 // every char skipped between two matched chars
+	maxGapPenalty    = -5 // the penalty of a single gap is at most this
+)
+
+// FuzzyMatch matches input as a subsequence of text like IsMatch, ignoring case and the
+// "-" in input. The score rewards a matched prefix, word boundaries and consecutive
+// chars, so "st" ranks "status" above "reset". matched are the rune indexes of text
+// matched by input.
+func FuzzyMatch(input, text string) (score int, matched []int, ok bool) {
+	query := []rune(strings.ToLower(strings.ReplaceAll(input, "-", "")))
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(query) == 0 {
+		return 0, nil, true
+	}
+	if len(lower) != len(runes) {
+		// lower case changed the length, match the text as it is
+		lower = runes
+	}
+	ok = false
+	// try every start of the first char, the rest is matched greedily
+	for start := range lower {
+		if lower[start] != query[0] {
+			continue
+		}
+		s, m, found := matchFrom(query, runes, lower, start)
+		if found && (!ok || s > score) {
+			score, matched, ok = s, m, true
+		}
+	}
+	return score, matched, ok
+}
+
+func matchFrom(query, runes, lower []rune, start int) (int, []int, bool) {
+	score := 0
+	matched := make([]int, 0, len(query))
+	last := -1
+	index := start
+	for _, c := range query {
+		for index < len(lower) && lower[index] != c {
+			index++
+		}
+		if index == len(lower) {
+			return 0, nil, false
+		}
+		score += scoreMatch
+		switch {
+		case index == 0:
+			score += scorePrefix
+		case isWordBoundary(runes, index):
+			score += scoreBoundary
+		}
+		if last >= 0 {
+			if index == last+1 {
+				score += scoreConsecutive
+			} else {
+				score += max((index-last-1)*scoreGap, maxGapPenalty)
+			}
+		}
+		matched = append(matched, index)
+		last = index
+		index++
+	}
+	return score, matched, true
+}
+
+// isWordBoundary reports whether runes[index] starts a word, it follows a separator or
+// is an upper case char behind a lower case one
+func isWordBoundary(runes []rune, index int) bool {
+	prev, current := runes[index-1], runes[index]
+	if strings.ContainsRune("-_ ./,:=", prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(current)
+}
+
+// rankSuggests scores suggests against the word typed, adds their rank and bonus and
+// sorts them by score, then by text. Hints stay on top and the loading row at the
+// bottom. The matched chars are remembered for highlighting.
+func rankSuggests(word string, suggests []Suggest, bonus func(s Suggest) int) []Suggest {
+	for index := range suggests {
+		s := &suggests[index]
+		s.score, s.matched = 0, nil
+		if isHintSuggest(*s) {
+			continue
+		}
+		if score, matched, ok := FuzzyMatch(word, s.Text); ok {
+			s.score, s.matched = score, matched
+		}
+		s.score += s.rank + bonus(*s)
+	}
+	rank := func(s Suggest) int {
+		switch s.SuggestType {
+		case SuggestOfPositional:
+			return 0
+		case SuggestOfLoading:
+			return 2
+		default:
+			return 1
+		}
+	}
+	sort.SliceStable(suggests, func(i, j int) bool {
+		a, b := suggests[i], suggests[j]
+		if rank(a) != rank(b) {
+			return rank(a) < rank(b)
+		}
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return a.Text < b.Text
+	})
+	return suggests
+}
@@ -0,0 +1,66 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		input, text string
+		score       int
+		matched     []int
+		ok          bool
+	}{
+		{"", "status", 0, nil, true},
+		{"st", "status", 13, []int{0, 1}, true},
+		{"ST", "status", 13, []int{0, 1}, true},
+		{"s-t", "status", 13, []int{0, 1}, true},
+		{"st", "reset", 1, []int{2, 4}, true},
+		{"gs", "git-status", 11, []int{0, 4}, true},
+		{"nm", "newName", 6, []int{0, 5}, true},
+		{"ts", "status", 1, []int{3, 5}, true}, // the later start has the smaller gap
+		{"xyz", "status", 0, nil, false},
+		{"stt", "st", 0, nil, false},
+	}
+	for _, tt := range tests {
+		score, matched, ok := FuzzyMatch(tt.input, tt.text)
+		if score != tt.score || !reflect.DeepEqual(matched, tt.matched) || ok != tt.ok {
+			t.Errorf("FuzzyMatch(%q, %q) = %d, %v, %v, want %d, %v, %v",
+				tt.input, tt.text, score, matched, ok, tt.score, tt.matched, tt.ok)
+		}
+	}
+}
+
+func TestRankSuggests(t *testing.T) {
+	noBonus := func(s Suggest) int { return 0 }
+	tests := []struct {
+		word     string
+		suggests []Suggest
+		bonus    func(s Suggest) int
+		want     []string
+	}{
+		{"st", []Suggest{{Text: "reset"}, {Text: "status"}, {Text: "start"}}, noBonus,
+			[]string{"start", "status", "reset"}},
+		// hints stay on top and the loading row at the bottom
+		{"st", []Suggest{
+			loadingSuggest, {Text: "reset"}, {Text: "<file>", SuggestType: SuggestOfPositional}, {Text: "status"},
+		}, noBonus, []string{"<file>", "status", "reset", "loading..."}},
+		{"", []Suggest{{Text: "b"}, {Text: "a", rank: 1}, {Text: "c"}}, noBonus, []string{"a", "b", "c"}},
+		{"st", []Suggest{{Text: "status"}, {Text: "reset"}}, func(s Suggest) int {
+			if s.Text == "reset" {
+				return 20
+			}
+			return 0
+		}, []string{"reset", "status"}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		for _, s := range rankSuggests(tt.word, tt.suggests, tt.bonus) {
+			got = append(got, s.Text)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("rankSuggests(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
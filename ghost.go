@@ -0,0 +1,64 @@
+package prompt
+
+import (
+	"strings"
+)
+
+// ghostText returns the most likely completion of the line behind the cursor, shown dim
+// after the cursor: the rest of the latest history cmd starting with the line, or the
+// rest of the first suggest starting with the word typed. Values are quoted like tab
+// inserts them, so "'my" is completed to "'my file'". It is empty if the cursor is not at
+// the end of the line or a suggest is chosen by tab.
+func (m *PromptModel) ghostText() string {
+	line := m.textInput.Value()
+	if !m.ghostTextEnabled || m.runCmdMark || line == "" || m.suggestIndex >= 0 ||
+		m.textInput.Position() < len([]rune(line)) {
+		return ""
+	}
+	for index := len(m.historys) - 1; index >= 0; index-- {
+		if history := m.historys[index]; len(history) > len(line) && strings.HasPrefix(history, line) {
+			return history[len(line):]
+		}
+	}
+	tokens, _ := tokenize(line)
+	if isInputtingNewToken(line, tokens) {
+		// nothing typed for the word yet
+		return ""
+	}
+	word := tokens[len(tokens)-1]
+	for _, s := range m.matchSuggests {
+		if isHintSuggest(s) || len(s.Text) <= len(word.Value) || !strings.HasPrefix(s.Text, word.Value) {
+			continue
+		}
+		text := s.Text
+		if s.SuggestType == SuggestOfValue {
+			text = QuoteArg(text)
+		}
+		// the typed word is replaced by the suggest, only an extension of it is shown
+		if completed := line[:word.Start] + text; strings.HasPrefix(completed, line) {
+			return completed[len(line):]
+		}
+	}
+	return ""
+}
+
+// acceptGhostText appends the ghost text to the line, only its next word if wordOnly.
+// It returns false if there is no ghost text.
+func (m *PromptModel) acceptGhostText(wordOnly bool) bool {
+	ghost := m.ghostText()
+	if ghost == "" {
+		return false
+	}
+	if wordOnly {
+		// the spaces before the word and the word
+		start := len(ghost) - len(strings.TrimLeft(ghost, " "))
+		if end := strings.IndexByte(ghost[start:], ' '); end >= 0 {
+			ghost = ghost[:start+end]
+		}
+	}
+	m.textInput.SetValue(m.textInput.Value() + ghost)
+	m.textInput.CursorEnd()
+	m.historyBuffers[m.historyIndex] = m.textInput.Value()
+	m.historyBufferPos = m.textInput.Position()
+	return true
+}
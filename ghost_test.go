@@ -0,0 +1,87 @@
+package prompt
+
+import (
+	"testing"
+)
+
+func TestGhostText(t *testing.T) {
+	m := newTestModel(t)
+	m.RegisterHandler(func(file string) {}, "open",
+		WithSuggests([]Suggest{{Text: "file", Enum: []string{"my file", "readme"}}}))
+	m.historys = []string{"deploy -env dev", "git status", "deploy -env prod"}
+	tests := []struct {
+		line   string
+		cursor int // -1 is the end of the line
+		want   string
+	}{
+		// the latest history cmd wins
+		{"de", -1, "ploy -env prod"},
+		{"git s", -1, "tatus"},
+		{"git status", -1, ""},
+		{"op", -1, "en"},
+		{"open -f", -1, "ile"},
+		{"open -file re", -1, "adme"},
+		{"open -file readme", -1, ""},
+		// values are quoted like tab inserts them
+		{"open -file 'my", -1, " file'"},
+		{"open -file my", -1, ""},
+		{"open ", -1, ""},
+		{"de", 1, ""},
+		{"", -1, ""},
+	}
+	for _, tt := range tests {
+		m.textInput.SetValue(tt.line)
+		m.historyBuffers[m.historyIndex], m.historyBufferPos = tt.line, len(tt.line)
+		m.updateSuggentList()
+		m.textInput.CursorEnd()
+		if tt.cursor >= 0 {
+			m.textInput.SetCursor(tt.cursor)
+		}
+		if got := m.ghostText(); got != tt.want {
+			t.Errorf("ghostText() of %q = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	// a suggest chosen by tab hides the ghost text
+	m.textInput.SetValue("op")
+	m.textInput.CursorEnd()
+	m.historyBuffers[m.historyIndex], m.historyBufferPos = "op", 2
+	m.updateSuggentList()
+	m.suggestIndex = 0
+	if got := m.ghostText(); got != "" {
+		t.Errorf("ghostText() = %q with a chosen suggest, want empty", got)
+	}
+	m.suggestIndex = -1
+	m.ghostTextEnabled = false
+	if got := m.ghostText(); got != "" {
+		t.Errorf("ghostText() = %q when disabled, want empty", got)
+	}
+}
+
+func TestAcceptGhostText(t *testing.T) {
+	m := newTestModel(t)
+	m.historys = []string{"deploy -env prod"}
+	m.textInput.SetValue("de")
+	m.textInput.CursorEnd()
+	m.historyBuffers[m.historyIndex], m.historyBufferPos = "de", 2
+	// word by word, the spaces before a word are accepted with it
+	for _, want := range []string{"deploy", "deploy -env", "deploy -env prod"} {
+		if !m.acceptGhostText(true) {
+			t.Fatalf("acceptGhostText(true) = false, want %q", want)
+		}
+		if got := m.textInput.Value(); got != want || m.historyBuffers[m.historyIndex] != want ||
+			m.historyBufferPos != len(want) {
+			t.Errorf("line = %q, buffer %q at %d, want %q", got, m.historyBuffers[m.historyIndex], m.historyBufferPos, want)
+		}
+	}
+	if m.acceptGhostText(true) {
+		t.Errorf("acceptGhostText(true) = true without ghost text")
+	}
+
+	m.textInput.SetValue("de")
+	m.textInput.CursorEnd()
+	m.historyBuffers[m.historyIndex], m.historyBufferPos = "de", 2
+	if !m.acceptGhostText(false) || m.textInput.Value() != "deploy -env prod" {
+		t.Errorf("acceptGhostText(false) line = %q, want %q", m.textInput.Value(), "deploy -env prod")
+	}
+}
@@ -0,0 +1,91 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	gnuLongPrefix  = "--"
+	gnuShortPrefix = "-"
+)
+
+// isGNUShortArg reports whether arg is a group of short flags in GNU mode, like -vq
+func isGNUShortArg(arg string) bool {
+	return isFlagArg(arg) && !strings.HasPrefix(arg, gnuLongPrefix)
+}
+
+// lookupShort returns the index of the suggest whose short name is short
+func (h *HandlerInfo) lookupShort(short string) int {
+	for index, s := range h.Suggests {
+		if !s.Positional && s.Short == short {
+			return index
+		}
+	}
+	return -1
+}
+
+// expandShortArg expands a group of short flags like -vq or -n5 to the args the flag
+// package understands (-v -q, -n=5). If the last short flag takes a value which is not
+// in the group, its suggest index is returned as expectValue, the next arg is its value.
+func (h *HandlerInfo) expandShortArg(arg string) (expanded []string, supplied []int, expectValue int, err error) {
+	expectValue = -1
+	shorts := []rune(strings.TrimPrefix(arg, gnuShortPrefix))
+	for i := 0; i < len(shorts); i++ {
+		short := string(shorts[i])
+		index := h.lookupShort(short)
+		if index < 0 {
+			return nil, nil, -1, fmt.Errorf("unknown short flag -%s in %s", short, arg)
+		}
+		supplied = append(supplied, index)
+		if h.isBoolParam(index) {
+			// -v=false sets a bool short flag explicitly
+			if i+1 < len(shorts) && shorts[i+1] == '=' {
+				return append(expanded, "-"+string(shorts[i:])), supplied, -1, nil
+			}
+			expanded = append(expanded, "-"+short)
+			continue
+		}
+		// a flag with value takes the rest of the group as value, -n5 or -n=5
+		if rest := strings.TrimPrefix(string(shorts[i+1:]), "="); i+1 < len(shorts) {
+			return append(expanded, "-"+short+"="+rest), supplied, -1, nil
+		}
+		return append(expanded, "-"+short), supplied, index, nil
+	}
+	return expanded, supplied, -1, nil
+}
+
+// expandGNUArgs rewrite GNU style args to the args the flag package understands. Long
+// flags need "--", a single "-" starts a group of short flags.
+func (h *HandlerInfo) expandGNUArgs(args []string) ([]string, error) {
+	expandedArgs := make([]string, 0, len(args))
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+		switch {
+		case arg == "--":
+			return append(expandedArgs, args[index:]...), nil
+		case strings.HasPrefix(arg, gnuLongPrefix):
+			expandedArgs = append(expandedArgs, arg)
+			name, _, hasValue := flagName(arg)
+			if suggest := h.lookupSuggest(name); suggest >= 0 && !hasValue && !h.isBoolParam(suggest) &&
+				index+1 < len(args) {
+				// keep the value as it is, even if it looks like a flag
+				index++
+				expandedArgs = append(expandedArgs, args[index])
+			}
+		case isGNUShortArg(arg):
+			expanded, _, expectValue, err := h.expandShortArg(arg)
+			if err != nil {
+				return nil, err
+			}
+			expandedArgs = append(expandedArgs, expanded...)
+			if expectValue >= 0 && index+1 < len(args) {
+				index++
+				expandedArgs = append(expandedArgs, args[index])
+			}
+		default:
+			expandedArgs = append(expandedArgs, arg)
+		}
+	}
+	return expandedArgs, nil
+}
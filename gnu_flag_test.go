@@ -0,0 +1,43 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandGNUArgs(t *testing.T) {
+	h := NewHandlerInfo("ls", func(verbose, quiet bool, num int, name string) {},
+		WithGNUFlags(),
+		WithSuggests([]Suggest{{Text: "verbose", Short: "v"}, {Text: "quiet", Short: "q"}, {Text: "num", Short: "n"}, {Text: "name"}}))
+	if err := h.CheckAndInitHandler(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{[]string{}, []string{}, false},
+		{[]string{"-vq"}, []string{"-v", "-q"}, false},
+		{[]string{"-n5"}, []string{"-n=5"}, false},
+		{[]string{"-n=5"}, []string{"-n=5"}, false},
+		{[]string{"-vn5"}, []string{"-v", "-n=5"}, false},
+		{[]string{"-vn", "5", "a"}, []string{"-v", "-n", "5", "a"}, false},
+		{[]string{"-n", "-v"}, []string{"-n", "-v"}, false},
+		{[]string{"-v=false"}, []string{"-v=false"}, false},
+		{[]string{"--num", "-3"}, []string{"--num", "-3"}, false},
+		{[]string{"--name=x", "--verbose", "a"}, []string{"--name=x", "--verbose", "a"}, false},
+		{[]string{"a", "--", "-vq"}, []string{"a", "--", "-vq"}, false},
+		{[]string{"-vx"}, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := h.expandGNUArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("expandGNUArgs(%q) err = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandGNUArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package prompt
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"reflect"
-	"strconv"
+	"sort"
 	"strings"
+	"time"
 )
 
 type Handler interface{} // func
@@ -14,6 +16,21 @@ type HandlerCallback func([]reflect.Value)
 
 type FlagSetInitFunc func(funcType reflect.Type) (*flag.FlagSet, []interface{}, error)
 
+// HandlerError is returned by Run when the handler returns a non-nil error as its
+// last result.
+type HandlerError struct {
+	Name string
+	Err  error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("handler[%s] return err: %v", e.Name, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error {
+	return e.Err
+}
+
 type HandlerInfo struct {
 	Handler           Handler
 	HandlerReflecType reflect.Type
@@ -23,6 +40,11 @@ type HandlerInfo struct {
 	SuggestPrefix    string
 	GetSuggestMethod GetSuggestFunc
 
+	// AsyncGetSuggestMethod replaces GetSuggestMethod for slow providers, it is called
+	// after the input is unchanged for SuggestDebounce, see WithAsyncGetSuggestMethod
+	AsyncGetSuggestMethod AsyncGetSuggestFunc
+	SuggestDebounce       time.Duration
+
 	Name     string
 	FlagsSet *flag.FlagSet
 	Params   []interface{}
@@ -33,6 +55,21 @@ type HandlerInfo struct {
 	FlagSetInitFuncImpl FlagSetInitFunc
 
 	ExitAfterRun bool
+
+	// SubHandlers are the child commands of this handler, key = child name. A handler
+	// with sub handlers may have a nil Handler, it is then only a command group.
+	SubHandlers map[string]*HandlerInfo
+	parent      *HandlerInfo
+
+	buildErr error // error while building the handler info, reported by CheckAndInitHandler
+
+	// GNUFlags parse flags GNU style: long flags with "--", short flags with "-" which can
+	// be combined like -vq, see WithGNUFlags
+	GNUFlags bool
+
+	// Variadic describes the variadic param of the handler, it gets the args left after
+	// the positional params. Text is "args" by default.
+	Variadic Suggest
 }
 
 func NewHandlerInfo(name string, handler Handler, opts ...HandlerInfoOption) *HandlerInfo {
@@ -52,6 +89,13 @@ func NewHandlerInfo(name string, handler Handler, opts ...HandlerInfoOption) *Ha
 	for _, opt := range opts {
 		opt(h)
 	}
+	// a single struct param describes its flags by field tags
+	if t, ok := h.structParam(); ok && len(h.Suggests) == 0 {
+		h.Suggests, h.buildErr = structSuggests(t)
+	}
+	if _, ok := h.variadicElem(); ok && h.Variadic.Text == "" {
+		h.Variadic.Text = "args"
+	}
 	return h
 }
 
@@ -65,73 +109,413 @@ func (h *HandlerInfo) InitParamsAndFlagSet() error {
 		return err
 	}
 	h.FlagsSet = flag.NewFlagSet(h.Name, flag.ContinueOnError)
+	h.FlagsSet.Usage = h.printUsage
 	h.Params = make([]interface{}, 0)
-	numIn := h.HandlerReflecType.NumIn()
-	for i := 0; i < numIn; i++ {
-		v := h.HandlerReflecType.In(i)
-		if _, ok := isSupportType(v.String()); !ok {
+	for i, v := range h.paramTypes() {
+		converter, ok := lookupConverter(v)
+		if !ok {
 			return fmt.Errorf("handler[%s] the %d param of type[%s] is not support, need register first",
 				h.Name, i, v.String())
 		}
-		if err := initIterfaceParams(&h.Suggests[i], v, h); err != nil {
+		if err := initIterfaceParams(&h.Suggests[i], converter, h); err != nil {
 			return fmt.Errorf("init handler[%s] the %d in param fail, err: %v", h.Name, i, err)
 		}
 	}
 	return nil
 }
 
-func (h *HandlerInfo) Run(cmd string) (err error) {
+// Run parse cmd and call the handler, cmd starts with the handler name.
+func (h *HandlerInfo) Run(cmd string) error {
+	return h.RunContext(context.Background(), cmd)
+}
+
+// RunContext is like Run, handlers whose first param is context.Context receive ctx.
+// The handler is called in its own goroutine and RunContext returns ctx.Err() as soon
+// as ctx is done, even if the handler doesn't watch ctx.
+func (h *HandlerInfo) RunContext(ctx context.Context, cmd string) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			err = fmt.Errorf("%v", r)
 		}
 	}()
-	fn := reflect.ValueOf(h.Handler)
+
+	tokens, err := tokenize(cmd)
+	if err != nil {
+		err = fmt.Errorf("can't split handler[%s] cmd, err: %v", h.Name, err)
+		return
+	}
+	if len(tokens) == 0 {
+		err = fmt.Errorf("handler[%s] cmd is empty", h.Name)
+		return
+	}
+
+	// dispatch to sub handler, the sub handler's cmd starts with its own name
+	if len(tokens) > 1 {
+		if subHandler, ok := h.SubHandlers[tokens[1].Value]; ok {
+			return subHandler.RunContext(ctx, cmd[tokens[1].Start:])
+		}
+	}
+	if h.Handler == nil {
+		if len(tokens) > 1 {
+			err = fmt.Errorf("unknown subcommand[%s] of handler[%s], valid subcommands: %s",
+				tokens[1].Value, h.FullName(), strings.Join(h.SubHandlerNames(), ", "))
+		} else {
+			err = fmt.Errorf("handler[%s] need a subcommand, valid subcommands: %s",
+				h.FullName(), strings.Join(h.SubHandlerNames(), ", "))
+		}
+		return
+	}
 
 	args := []reflect.Value{}
+	offset := h.paramOffset()
+	if offset > 0 {
+		args = append(args, reflect.ValueOf(ctx))
+	}
 	if h.UseFlagSet {
+		// param values are copied into args, reset flag set for the next run
+		defer func() {
+			if err := h.InitParamsAndFlagSet(); err != nil {
+				panic(err)
+			}
+		}()
 		// parse param
-		if err = h.FlagsSet.Parse(strings.Split(cmd, " ")[1:]); err != nil {
+		var rest []string
+		if rest, err = h.parseArgs(tokenValues(tokens[1:])); err != nil {
 			err = fmt.Errorf("can't parse handler[%s] args, err: %v", h.Name, err)
 			return
 		}
-		numIn := h.HandlerReflecType.NumIn()
-		for i := 0; i < numIn; i++ {
-			v := h.HandlerReflecType.In(i)
-			args = append(args, convertParam(h.Params[i], v))
+		variadicArgs, violations := h.variadicArgs(rest)
+		if violations = append(h.checkParams(), violations...); len(violations) > 0 {
+			err = &ParamError{Name: h.FullName(), Violations: violations}
+			return
+		}
+		args = append(args, h.paramArgs()...)
+		args = append(args, variadicArgs...)
+	} else {
+		// handler without flag set gets the raw text after the handler name
+		args = append(args, reflect.ValueOf(strings.TrimSpace(cmd[tokens[0].End:])))
+	}
+
+	return h.call(ctx, args)
+}
+
+func (h *HandlerInfo) call(ctx context.Context, args []reflect.Value) error {
+	done := make(chan error, 1)
+	go func() {
+		var err error = nil
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+			done <- err
+		}()
+		results := reflect.ValueOf(h.Handler).Call(args)
+		if h.Callback != nil {
+			h.Callback(results)
+		}
+		if numOut := len(results); numOut > 0 && h.HandlerReflecType.Out(numOut-1) == errorType &&
+			!results[numOut-1].IsNil() {
+			err = &HandlerError{Name: h.FullName(), Err: results[numOut-1].Interface().(error)}
 		}
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
 	}
+}
 
-	results := fn.Call(args)
-	if h.Callback != nil {
-		h.Callback(results)
+// parseArgs parse the flags in args and fill the positional params with the other args
+// in order, flags and positional args can be mixed. Args after "--" are all positional.
+// The args left after the positional params are returned for the variadic param.
+func (h *HandlerInfo) parseArgs(args []string) ([]string, error) {
+	if h.GNUFlags {
+		var err error
+		if args, err = h.expandGNUArgs(args); err != nil {
+			return nil, err
+		}
 	}
-	if err := h.InitParamsAndFlagSet(); err != nil {
-		panic(err)
+	positionals := []string{}
+	for {
+		if err := h.FlagsSet.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := h.FlagsSet.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positionals = append(positionals, rest...)
+			break
+		}
+		positionals = append(positionals, rest[0])
+		args = rest[1:]
 	}
-	return
+
+	slots := h.positionalSuggests()
+	if _, ok := h.variadicElem(); !ok && len(positionals) > len(slots) {
+		return nil, fmt.Errorf("too many args, want %d positional args but got %d: %s",
+			len(slots), len(positionals), strings.Join(positionals, " "))
+	}
+	for index, arg := range positionals {
+		if index >= len(slots) {
+			return positionals[index:], nil
+		}
+		s := h.Suggests[slots[index]]
+		param, ok := h.Params[slots[index]].(*paramValue)
+		if !ok {
+			return nil, fmt.Errorf("positional param[%s] is not support by custom flag set", s.Text)
+		}
+		if err := param.Set(arg); err != nil {
+			return nil, fmt.Errorf("invalid value %q for positional param <%s>: %v", arg, s.Text, err)
+		}
+	}
+	return []string{}, nil
+}
+
+// variadicElem returns the element type of the variadic param of the handler
+func (h *HandlerInfo) variadicElem() (reflect.Type, bool) {
+	if h.HandlerReflecType == nil || h.HandlerReflecType.Kind() != reflect.Func ||
+		!h.HandlerReflecType.IsVariadic() {
+		return nil, false
+	}
+	return h.HandlerReflecType.In(h.HandlerReflecType.NumIn() - 1).Elem(), true
+}
+
+// variadicArgs convert the args left after the positional params to the element type
+// of the variadic param, the validators of the Variadic suggest check each arg
+func (h *HandlerInfo) variadicArgs(rest []string) ([]reflect.Value, []string) {
+	values := []reflect.Value{}
+	violations := []string{}
+	elem, ok := h.variadicElem()
+	if !ok {
+		return values, violations
+	}
+	converter, _ := lookupConverter(elem)
+	label := "<" + h.Variadic.Text + "...>"
+	for _, arg := range rest {
+		v, err := converter.Parse(arg)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("%s: %v", label, parseError(converter, arg, err)))
+			continue
+		}
+		for _, violation := range h.Variadic.validate(converter, v) {
+			violations = append(violations, fmt.Sprintf("%s: %s", label, violation))
+		}
+		values = append(values, reflect.ValueOf(v))
+	}
+	return values, violations
+}
+
+// suggestIndex returns the index of the suggest named name, -1 if not found
+func (h *HandlerInfo) suggestIndex(name string) int {
+	for index, s := range h.Suggests {
+		if s.Text == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// paramConverter returns the converter of the param bound to the suggest named name
+func (h *HandlerInfo) paramConverter(name string) (*Converter, bool) {
+	index := h.suggestIndex(name)
+	if index < 0 || h.HandlerReflecType == nil || !h.UseFlagSet {
+		return nil, false
+	}
+	types := h.paramTypes()
+	if index >= len(types) {
+		return nil, false
+	}
+	return lookupConverter(types[index])
+}
+
+// valueSuggests returns the values completed for the param whose value is typed, see
+// valueCompleter
+func (h *HandlerInfo) valueSuggests(inputCtx inputContext) []Suggest {
+	suggests := make([]Suggest, 0)
+	var complete CompleteFunc
+	if inputCtx.Suggest >= 0 {
+		if converter, ok := h.paramConverter(h.Suggests[inputCtx.Suggest].Text); ok {
+			complete = valueCompleter(&h.Suggests[inputCtx.Suggest], converter)
+		}
+	} else if elem, isVariadic := h.variadicElem(); isVariadic && inputCtx.Kind == inputPositional {
+		// the args after the positional params are the values of the variadic param
+		if converter, ok := lookupConverter(elem); ok {
+			complete = valueCompleter(&h.Variadic, converter)
+		}
+	}
+	if complete == nil {
+		return suggests
+	}
+	for _, s := range complete(inputCtx.Value) {
+		s.Text = inputCtx.Prefix + s.Text
+		s.SuggestType = SuggestOfValue
+		suggests = append(suggests, s)
+	}
+	return suggests
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// paramOffset returns 1 if the first param of handler is context.Context, the params
+// bound to suggests start behind it.
+func (h *HandlerInfo) paramOffset() int {
+	if h.HandlerReflecType.NumIn() > 0 && h.HandlerReflecType.In(0) == contextType {
+		return 1
+	}
+	return 0
+}
+
+// Usage returns the usage line of the handler, like "cp [-r] [-n int] <src> <dst> [files...]"
+func (h *HandlerInfo) Usage() string {
+	parts := []string{}
+	for _, part := range h.usageParts(false) {
+		parts = append(parts, part.Text)
+	}
+	return strings.Join(parts, " ")
+}
+
+// the Suggest of the usage parts not describing a suggest
+const (
+	usageOfName     = -1
+	usageOfVariadic = -2
+)
+
+// usagePart is a part of the usage line, Suggest is the index of the suggest it
+// describes, usageOfName or usageOfVariadic
+type usagePart struct {
+	Text    string
+	Suggest int
+}
+
+// usageParts returns the parts of the usage line, withDefaults adds the default values
+// other than the zero value to the flags, like "-n int=5"
+func (h *HandlerInfo) usageParts(withDefaults bool) []usagePart {
+	parts := []usagePart{{h.FullName(), usageOfName}}
+	types := []reflect.Type{}
+	if h.UseFlagSet && h.FlagSetInitFuncImpl == nil {
+		types = h.paramTypes()
+	}
+	typeName := func(index int) string {
+		if index >= len(types) || isBoolType(types[index]) {
+			return ""
+		}
+		name := " " + types[index].String()
+		if !withDefaults {
+			return name
+		}
+		if converter, ok := lookupConverter(types[index]); ok && h.Suggests[index].Default != nil {
+			value := converter.Format(h.Suggests[index].Default)
+			if value != converter.Format(getDefaultValue(types[index])) {
+				name += "=" + value
+			}
+		}
+		return name
+	}
+	for index, s := range h.Suggests {
+		if s.Positional {
+			continue
+		}
+		part := h.SuggestPrefix + s.Text + typeName(index)
+		if !s.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, usagePart{part, index})
+	}
+	for _, index := range h.positionalSuggests() {
+		parts = append(parts, usagePart{"<" + h.Suggests[index].Text + ">", index})
+	}
+	if _, ok := h.variadicElem(); ok {
+		parts = append(parts, usagePart{"[" + h.Variadic.Text + "...]", usageOfVariadic})
+	}
+	return parts
+}
+
+// printUsage is the Usage of the flag set, it prints the usage line, the flags and the
+// variadic param
+func (h *HandlerInfo) printUsage() {
+	out := h.FlagsSet.Output()
+	fmt.Fprintf(out, "usage: %s\n", h.Usage())
+	h.FlagsSet.PrintDefaults()
+	if _, ok := h.variadicElem(); ok && h.Variadic.Description != "" {
+		fmt.Fprintf(out, "  %s...\n    \t%s\n", h.Variadic.Text, h.Variadic.Description)
+	}
+}
+
+// FullName returns the name of the handler prefixed with the names of its parents,
+// like "user add".
+func (h *HandlerInfo) FullName() string {
+	if h.parent == nil {
+		return h.Name
+	}
+	return h.parent.FullName() + " " + h.Name
+}
+
+// SubHandlerNames returns the sorted names of the sub handlers.
+func (h *HandlerInfo) SubHandlerNames() []string {
+	names := make([]string, 0, len(h.SubHandlers))
+	for name := range h.SubHandlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func (h *HandlerInfo) CheckAndInitHandler() error {
 	if h.Name == "" {
 		return fmt.Errorf("handler name can't be empty")
 	}
+	if h.buildErr != nil {
+		return fmt.Errorf("build handler[%s] fail, err: %v", h.Name, h.buildErr)
+	}
+	for _, subHandler := range h.SubHandlers {
+		if err := subHandler.CheckAndInitHandler(); err != nil {
+			return fmt.Errorf("check sub handler of handler[%s] fail, err: %v", h.Name, err)
+		}
+	}
+	if h.Handler == nil {
+		if len(h.SubHandlers) == 0 {
+			return fmt.Errorf("handler[%s] is nil and has no sub handler", h.Name)
+		}
+		return nil
+	}
 	if h.HandlerReflecType.Kind() != reflect.Func {
 		return fmt.Errorf("handler[%s] is not func", h.Name)
 	}
 	if errMsg := checkSuggest(h.Suggests); errMsg != "" {
 		return fmt.Errorf("check suggest of handler[%s] fail, err: %s", h.Name, errMsg)
 	}
+	offset := h.paramOffset()
 	if !h.UseFlagSet {
-		if h.HandlerReflecType.NumIn() != 1 || h.HandlerReflecType.In(0).Kind() != reflect.String {
+		if h.HandlerReflecType.NumIn() != offset+1 || h.HandlerReflecType.In(offset).Kind() != reflect.String {
 			return fmt.Errorf("handler[%s] not use flagset, should have 1 in param which type is string",
 				h.Name)
 		}
 		return nil
 	}
-	if h.HandlerReflecType.NumIn() != len(h.Suggests) {
+	if elem, ok := h.variadicElem(); ok {
+		if _, ok := lookupConverter(elem); !ok {
+			return fmt.Errorf("handler[%s] variadic param of type[%s] is not support, need register first",
+				h.Name, elem.String())
+		}
+		if err := checkValidators(&h.Variadic, elem); err != nil {
+			return fmt.Errorf("check suggest of handler[%s] fail, err: %v", h.Name, err)
+		}
+	}
+	paramTypes := h.paramTypes()
+	if numParam := len(paramTypes); numParam != len(h.Suggests) {
 		return fmt.Errorf("handler[%s] has %d in param, but suggest num is %d",
-			h.Name, h.HandlerReflecType.NumIn(), len(h.Suggests))
+			h.Name, numParam, len(h.Suggests))
+	}
+	for index, t := range paramTypes {
+		if err := checkValidators(&h.Suggests[index], t); err != nil {
+			return fmt.Errorf("check suggest of handler[%s] fail, err: %v", h.Name, err)
+		}
 	}
 	if err := h.InitParamsAndFlagSet(); err != nil {
 		return err
@@ -148,41 +532,48 @@ func checkSuggest(suggests []Suggest) (errMsg string) {
 		} else {
 			errMsg += fmt.Sprintf("suggest[%s] is duplicate|", suggest.Text)
 		}
+		names := suggest.Aliases
+		if suggest.Short != "" {
+			names = append([]string{suggest.Short}, names...)
+		}
+		for _, name := range names {
+			if _, ok := tempMap[name]; !ok {
+				tempMap[name] = true
+			} else {
+				errMsg += fmt.Sprintf("alias[%s] of suggest[%s] is duplicate|", name, suggest.Text)
+			}
+		}
+		if len([]rune(suggest.Short)) > 1 {
+			errMsg += fmt.Sprintf("short name[%s] of suggest[%s] should be one char|", suggest.Short, suggest.Text)
+		}
 	}
 	return
 }
 
-func initIterfaceParams(suggest *Suggest, valueType reflect.Type, h *HandlerInfo) error {
+func initIterfaceParams(suggest *Suggest, converter *Converter, h *HandlerInfo) error {
+	valueType := converter.Type
 	if isNil(suggest.Default) {
-		defaultValue, err := getDefaultValue(valueType)
-		if err != nil {
-			return fmt.Errorf("getDefaultValue fail, err: %v", err)
-		}
-		suggest.Default = defaultValue
+		suggest.Default = getDefaultValue(valueType)
 	} else {
 		defaultType := reflect.TypeOf(suggest.Default)
-		if defaultType.String() != valueType.String() {
+		if defaultType != valueType {
 			return fmt.Errorf("default value type[%s] is defferrnt with in param type[%s]",
 				defaultType.String(), valueType.String())
 		}
 	}
-	var param interface{} = nil
-	switch valueType.String() {
-	case "string":
-		param = h.FlagsSet.String(suggest.Text, suggest.Default.(string), suggest.Description)
-	case "int", "int8", "int16", "int32", "int64":
-		param = h.FlagsSet.Int64(suggest.Text, intxToInt64(suggest.Default), suggest.Description)
-	case "float64", "float32":
-		param = h.FlagsSet.Float64(suggest.Text, floatxToFloat64(suggest.Default), suggest.Description)
-	case "bool":
-		param = h.FlagsSet.Bool(suggest.Text, suggest.Default.(bool), suggest.Description)
-	case "uint", "uint8", "uint16", "uint32", "uint64":
-		param = h.FlagsSet.Uint64(suggest.Text, uintxToUint64(suggest.Default), suggest.Description)
-	default:
-		return fmt.Errorf("value type[%s] is not support by flag set", valueType.String())
-	}
-
+	param := newParamValue(converter, suggest.Default)
 	h.Params = append(h.Params, param)
+	if suggest.Positional {
+		// positional params are filled by parseArgs, not flags
+		return nil
+	}
+	h.FlagsSet.Var(param, suggest.Text, suggest.Description)
+	if suggest.Short != "" {
+		h.FlagsSet.Var(param, suggest.Short, suggest.Description)
+	}
+	for _, alias := range suggest.Aliases {
+		h.FlagsSet.Var(param, alias, suggest.Description)
+	}
 	return nil
 }
 
@@ -196,28 +587,6 @@ func isNil(i interface{}) bool {
 	}
 }
 
-func getDefaultValue(valueType reflect.Type) (interface{}, error) {
-	zeroValue := reflect.Zero(valueType)
-	defaultValue := fmt.Sprintf("%v", zeroValue)
-	valueTypeString := valueType.String()
-	var value interface{} = nil
-	var err error = nil
-	switch valueTypeString {
-	case "string":
-		return defaultValue, nil
-	case "int", "int8", "int16", "int32", "int64":
-		value, err = strconv.ParseInt(defaultValue, 10, 64)
-		value = int64ToIntx(value, valueType)
-	case "float64", "float32":
-		value, err = strconv.ParseFloat(defaultValue, 64)
-		value = float64ToFloatx(value, valueType)
-	case "bool":
-		return defaultValue == "true", nil
-	case "uint", "uint8", "uint16", "uint32", "uint64":
-		value, err = strconv.ParseUint(defaultValue, 10, 64)
-		value = uint64ToUintx(value, valueType)
-	default:
-		err = fmt.Errorf("value type[%s] is not support", valueType.String())
-	}
-	return value, err
+func getDefaultValue(valueType reflect.Type) interface{} {
+	return reflect.Zero(valueType).Interface()
 }
@@ -0,0 +1,100 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseArgs(t *testing.T) {
+	cp := NewHandlerInfo("cp", func(recursive bool, src, dst string, sizes ...int) {},
+		WithSuggests([]Suggest{{Text: "r"}, {Text: "src", Positional: true}, {Text: "dst", Positional: true}}),
+		WithVariadicSuggest(Suggest{Text: "sizes", Min: 1}))
+	mv := NewHandlerInfo("mv", func(src, dst string) {},
+		WithSuggests([]Suggest{{Text: "src", Positional: true}, {Text: "dst", Positional: true}}))
+	for _, h := range []*HandlerInfo{cp, mv} {
+		if err := h.CheckAndInitHandler(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		h              *HandlerInfo
+		args           []string
+		wantParams     []interface{}
+		wantVariadic   []interface{}
+		wantViolations int
+		wantErr        bool
+	}{
+		{cp, []string{"a", "b"}, []interface{}{false, "a", "b"}, []interface{}{}, 0, false},
+		{cp, []string{"-r", "a", "b", "1", "2"}, []interface{}{true, "a", "b"}, []interface{}{1, 2}, 0, false},
+		{cp, []string{"a", "-r", "b"}, []interface{}{true, "a", "b"}, []interface{}{}, 0, false},
+		{cp, []string{"--", "-r", "b", "3"}, []interface{}{false, "-r", "b"}, []interface{}{3}, 0, false},
+		{cp, []string{"a", "b", "x", "0", "5"}, []interface{}{false, "a", "b"}, []interface{}{0, 5}, 2, false},
+		{cp, []string{"-x", "a"}, nil, nil, 0, true},
+		{mv, []string{"a", "b"}, []interface{}{"a", "b"}, []interface{}{}, 0, false},
+		{mv, []string{"a", "b", "c"}, nil, nil, 0, true},
+	}
+	for _, tt := range tests {
+		if err := tt.h.InitParamsAndFlagSet(); err != nil {
+			t.Fatal(err)
+		}
+		rest, err := tt.h.parseArgs(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s parseArgs(%q) err = %v, wantErr %v", tt.h.Name, tt.args, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		params := []interface{}{}
+		for _, param := range tt.h.Params {
+			params = append(params, param.(*paramValue).Get())
+		}
+		if !reflect.DeepEqual(params, tt.wantParams) {
+			t.Errorf("%s parseArgs(%q) params = %v, want %v", tt.h.Name, tt.args, params, tt.wantParams)
+		}
+		values, violations := tt.h.variadicArgs(rest)
+		variadic := []interface{}{}
+		for _, v := range values {
+			variadic = append(variadic, v.Interface())
+		}
+		if !reflect.DeepEqual(variadic, tt.wantVariadic) || len(violations) != tt.wantViolations {
+			t.Errorf("%s variadicArgs(%q) = %v, %q, want %v and %d violations",
+				tt.h.Name, rest, variadic, violations, tt.wantVariadic, tt.wantViolations)
+		}
+	}
+}
+
+type ctxKey struct{}
+
+func TestRunContextCancel(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	tests := []struct {
+		name    string
+		handler Handler
+	}{
+		// the handler watches ctx
+		{"watch", func(ctx context.Context) {
+			if ctx.Value(ctxKey{}) != "value" {
+				panic("ctx not passed")
+			}
+			<-ctx.Done()
+		}},
+		// RunContext returns without waiting for the handler
+		{"ignore", func() { <-block }},
+	}
+	for _, tt := range tests {
+		h := NewHandlerInfo(tt.name, tt.handler)
+		if err := h.CheckAndInitHandler(); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "value"))
+		time.AfterFunc(10*time.Millisecond, cancel)
+		if err := h.RunContext(ctx, tt.name); !errors.Is(err, context.Canceled) {
+			t.Errorf("RunContext of handler[%s] = %v, want %v", tt.name, err, context.Canceled)
+		}
+	}
+}
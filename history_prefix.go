@@ -0,0 +1,29 @@
+package prompt
+
+import "strings"
+
+// historyPrefixIndex returns the index of historyBuffers up or down walks to when the
+// history is filtered by prefix, like history-beginning-search of zsh: only the entries
+// starting with the line before the cursor are visited, the line being edited is always
+// the newest entry. The prefix is kept while walking and taken again once the line is
+// edited. Entries equal to the shown line are skipped, so duplicates are visited once.
+func (m *PromptModel) historyPrefixIndex(up bool) int {
+	line := m.textInput.Value()
+	if line != m.historyPrefixLine {
+		m.historyPrefix = string([]rune(line)[:min(m.textInput.Position(), len([]rune(line)))])
+	}
+	step := 1
+	if up {
+		step = -1
+	}
+	for index := m.historyIndex + step; index >= 0 && index < len(m.historys); index += step {
+		if buffer := m.historyBuffers[index]; buffer != line && strings.HasPrefix(buffer, m.historyPrefix) {
+			return index
+		}
+	}
+	if up {
+		// no older match, stay at the entry
+		return m.historyIndex
+	}
+	return len(m.historys)
+}
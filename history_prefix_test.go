@@ -0,0 +1,43 @@
+package prompt
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHistoryPrefixIndex(t *testing.T) {
+	m := newHistoryModel(t, "git status", "ls", "git push", "git status", "make")
+	m.historyPrefixSearch = true
+	up, down := tea.KeyMsg{Type: tea.KeyUp}, tea.KeyMsg{Type: tea.KeyDown}
+	steps := []struct {
+		key  tea.KeyMsg
+		line string
+	}{
+		{tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("git")}, "git"},
+		{up, "git status"},
+		{up, "git push"},
+		// "ls" doesn't match and the older "git status" is visited again as it isn't shown
+		{up, "git status"},
+		// no older match
+		{up, "git status"},
+		{down, "git push"},
+		{down, "git status"},
+		// the line being edited is the newest entry
+		{down, "git"},
+		{down, "git"},
+		{up, "git status"},
+		// an edited line is the new prefix
+		{tea.KeyMsg{Type: tea.KeyBackspace}, "git statu"},
+		{up, "git status"},
+		// the edit is kept in its entry
+		{down, "git statu"},
+		{down, "git"},
+	}
+	for index, step := range steps {
+		m.Update(step.key)
+		if line := m.textInput.Value(); line != step.line {
+			t.Fatalf("step %d: line = %q, want %q", index, line, step.line)
+		}
+	}
+}
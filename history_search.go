@@ -0,0 +1,142 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historySearch is the state of the incremental history search started by ctrl+r or
+// ctrl+s. The line is only replaced by the match when the search is accepted.
+type historySearch struct {
+	query   string
+	match   int  // index of the matched cmd in historys, -1 if none
+	reverse bool // search older cmds, ctrl+r, or newer ones, ctrl+s
+	failing bool // no more cmd matches the query in the search direction
+}
+
+// startSearch enters the history search mode
+func (m *PromptModel) startSearch(reverse bool) {
+	m.search = &historySearch{match: -1, reverse: reverse}
+	m.suggestIndex = -1
+}
+
+// updateSearch handles the keys in the history search mode: typed chars extend the
+// query, ctrl+r and ctrl+s cycle through older and newer matches, backspace shrinks the
+// query, esc, ctrl+g and ctrl+c abort. Enter accepts the match and runs it, other keys
+// accept it and are handled as usual, like bash does.
+func (m *PromptModel) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	search := m.search
+	switch msg.String() {
+	case "ctrl+r", "ctrl+s":
+		search.reverse = msg.String() == "ctrl+r"
+		if search.query == "" {
+			// like bash, an empty query searches the last one again
+			search.query = m.lastSearchQuery
+			search.match = -1
+		}
+		m.findMatch(search.match+m.searchStep(), true)
+		return m, nil
+	case "backspace":
+		if runes := []rune(search.query); len(runes) > 0 {
+			search.query = string(runes[:len(runes)-1])
+		}
+		search.match = -1
+		m.findMatch(m.searchStart(), false)
+		return m, nil
+	case "esc", "ctrl+g", "ctrl+c":
+		m.stopSearch()
+		return m, nil
+	}
+	if (msg.Type == tea.KeyRunes && !msg.Alt) || msg.Type == tea.KeySpace {
+		search.query += string(msg.Runes)
+		// the current match is kept while it matches the longer query
+		start := search.match
+		if start < 0 {
+			start = m.searchStart()
+		}
+		m.findMatch(start, false)
+		return m, nil
+	}
+	m.acceptSearch()
+	return m.update(msg)
+}
+
+// searchStep returns the step of the search through historys
+func (m *PromptModel) searchStep() int {
+	if m.search.reverse {
+		return -1
+	}
+	return 1
+}
+
+// searchStart returns the index of historys a new search starts at
+func (m *PromptModel) searchStart() int {
+	if m.search.reverse {
+		return len(m.historys) - 1
+	}
+	return 0
+}
+
+// findMatch finds the next cmd containing the query from historys[start] in the search
+// direction. skipSame skips cmds equal to the current match, so duplicates in history
+// are shown once while cycling. The current match is kept if nothing is found.
+func (m *PromptModel) findMatch(start int, skipSame bool) {
+	search := m.search
+	if search.match < 0 && (start < 0 || start >= len(m.historys)) {
+		start = m.searchStart()
+	}
+	current := ""
+	if search.match >= 0 {
+		current = m.historys[search.match]
+	}
+	for index := start; index >= 0 && index < len(m.historys); index += m.searchStep() {
+		if cmd := m.historys[index]; strings.Contains(cmd, search.query) && !(skipSame && cmd == current) {
+			search.match, search.failing = index, false
+			return
+		}
+	}
+	search.failing = true
+}
+
+// acceptSearch puts the match into the line and leaves the search mode
+func (m *PromptModel) acceptSearch() {
+	if m.search.match >= 0 {
+		m.textInput.SetValue(m.historys[m.search.match])
+		m.textInput.CursorEnd()
+		m.historyBuffers[m.historyIndex] = m.textInput.Value()
+		m.historyBufferPos = m.textInput.Position()
+	}
+	m.stopSearch()
+}
+
+// stopSearch leaves the search mode, the line is kept as it is
+func (m *PromptModel) stopSearch() {
+	if m.search.query != "" {
+		m.lastSearchQuery = m.search.query
+	}
+	m.search = nil
+}
+
+// searchView renders the mini prompt of the search, like "(reverse-i-search)`dep': deploy
+// -env dev" with the query highlighted in the match
+func (m *PromptModel) searchView() string {
+	search := m.search
+	name := "i-search"
+	if search.reverse {
+		name = "reverse-i-search"
+	}
+	if search.failing {
+		name = "failed " + name
+	}
+	match := ""
+	if search.match >= 0 {
+		match = m.historys[search.match]
+		if index := strings.Index(match, search.query); index >= 0 && search.query != "" {
+			end := index + len(search.query)
+			match = match[:index] + m.matchStyle.Render(match[index:end]) + match[end:]
+		}
+	}
+	return fmt.Sprintf("(%s)`%s': %s", name, search.query, match)
+}
@@ -0,0 +1,74 @@
+package prompt
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newHistoryModel(t *testing.T, historys ...string) *PromptModel {
+	m := newTestModel(t, WithoutPrintCmd())
+	m.historys = historys
+	m.historyBuffers = append(append([]string{}, historys...), "")
+	m.historyIndex = len(historys)
+	return m
+}
+
+func TestUpdateSearch(t *testing.T) {
+	m := newHistoryModel(t, "deploy dev", "git status", "deploy prod", "ls", "deploy prod")
+	runes := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	steps := []struct {
+		key     tea.KeyMsg
+		query   string
+		match   int
+		failing bool
+	}{
+		{tea.KeyMsg{Type: tea.KeyCtrlR}, "", -1, false},
+		{runes("dep"), "dep", 4, false},
+		// the match is kept while it matches the longer query
+		{runes("l"), "depl", 4, false},
+		// duplicates are skipped while cycling
+		{tea.KeyMsg{Type: tea.KeyCtrlR}, "depl", 0, false},
+		{tea.KeyMsg{Type: tea.KeyCtrlR}, "depl", 0, true},
+		{tea.KeyMsg{Type: tea.KeyCtrlS}, "depl", 2, false},
+		{runes("x"), "deplx", 2, true},
+		{tea.KeyMsg{Type: tea.KeyBackspace}, "depl", 0, false},
+	}
+	for index, step := range steps {
+		m.Update(step.key)
+		if m.search == nil {
+			t.Fatalf("step %d: search stopped", index)
+		}
+		if m.search.query != step.query || m.search.match != step.match || m.search.failing != step.failing {
+			t.Errorf("step %d: search = %q at %d failing %v, want %q at %d failing %v", index,
+				m.search.query, m.search.match, m.search.failing, step.query, step.match, step.failing)
+		}
+	}
+	// abort keeps the line
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.search != nil || m.textInput.Value() != "" {
+		t.Errorf("search = %v, line = %q after esc, want no search and empty line", m.search, m.textInput.Value())
+	}
+
+	// ctrl+r with an empty query searches the last query again, other keys accept
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.search.query != "depl" || m.search.match != 4 {
+		t.Errorf("search = %q at %d, want the last query %q at 4", m.search.query, m.search.match, "depl")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if m.search != nil || m.textInput.Value() != "deploy prod" || m.historyBuffers[m.historyIndex] != "deploy prod" {
+		t.Errorf("line = %q after accept, want %q", m.textInput.Value(), "deploy prod")
+	}
+
+	// enter accepts and runs the match
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m.Update(runes("git"))
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || !m.runCmdMark {
+		t.Errorf("enter in search didn't run the match")
+	}
+	if last := m.historys[len(m.historys)-1]; last != "git status" {
+		t.Errorf("last history = %q after enter, want %q", last, "git status")
+	}
+}
@@ -0,0 +1,99 @@
+package prompt
+
+const maxHistoryValues = 10 // values of a param offered from history
+
+// argValue is the value of the param of a suggest in the args of a cmd
+type argValue struct {
+	Suggest int
+	Value   string
+}
+
+// argValues returns the values of the flags and positional params in args, bool flags
+// are skipped
+func (h *HandlerInfo) argValues(args []string) []argValue {
+	values := []argValue{}
+	w := h.newArgWalker()
+	for _, arg := range args {
+		walked := w.next(arg)
+		switch {
+		case walked.Suggest < 0:
+		case walked.Kind == inputFlagValue, walked.Kind == inputPositional,
+			walked.Kind == inputFlagName && walked.HasValue && !h.isBoolParam(walked.Suggest):
+			values = append(values, argValue{walked.Suggest, walked.Value})
+		}
+	}
+	return values
+}
+
+// paramHistoryValues returns the values used for the param of the suggest at index of h
+// in history, the most recent first. They are collected from the uses when needed.
+func (m *PromptModel) paramHistoryValues(h *HandlerInfo, index int) []string {
+	if m.paramHistory == nil {
+		m.paramHistory = map[string][]string{}
+		for _, use := range m.historyUses {
+			m.collectValues(use)
+		}
+	}
+	return m.paramHistory[frecencyKey(h, h.Suggests[index].Text)]
+}
+
+// collectValues puts the param values of use in front of the values of the params
+func (m *PromptModel) collectValues(use historyUse) {
+	tokens, _ := tokenize(use.cmd)
+	segments, err := splitCommandChain(tokens)
+	if err != nil {
+		return
+	}
+	for _, segment := range segments {
+		words := tokenValues(segment.Tokens)
+		handler, depth := m.resolveHandler(words)
+		if handler == nil || !handler.UseFlagSet || handler.FlagSetInitFuncImpl != nil {
+			continue
+		}
+		for _, v := range handler.argValues(words[depth+1:]) {
+			key := frecencyKey(handler, handler.Suggests[v.Suggest].Text)
+			values := []string{v.Value}
+			for _, value := range m.paramHistory[key] {
+				if value != v.Value && len(values) < maxHistoryValues {
+					values = append(values, value)
+				}
+			}
+			m.paramHistory[key] = values
+		}
+	}
+}
+
+// historyValueSuggests adds the values used in history to the suggests of the value
+// typed in input of h. A value already suggested, e.g. by Enum, is ranked as history
+// value instead of added again.
+func (m *PromptModel) historyValueSuggests(h *HandlerInfo, input string, suggests []Suggest) []Suggest {
+	if !m.historyValues || !h.UseFlagSet || h.FlagSetInitFuncImpl != nil {
+		return suggests
+	}
+	_, _, inputs := typedWords(input)
+	if len(inputs) < 2 {
+		return suggests
+	}
+	inputCtx := h.analyzeInputs(inputs)
+	if inputCtx.Suggest < 0 || inputCtx.Kind == inputFlagName || h.isBoolParam(inputCtx.Suggest) {
+		return suggests
+	}
+	rank := maxHistoryValues
+	for _, value := range m.paramHistoryValues(h, inputCtx.Suggest) {
+		if !IsMatch(inputCtx.Value, value) {
+			continue
+		}
+		text := inputCtx.Prefix + value
+		found := false
+		for index := range suggests {
+			if suggests[index].Text == text && suggests[index].SuggestType == SuggestOfValue {
+				suggests[index].rank, found = rank, true
+			}
+		}
+		if !found {
+			suggests = append(suggests, Suggest{Text: text, Description: "history", SuggestType: SuggestOfValue, rank: rank})
+		}
+		rank--
+	}
+	return suggests
+}
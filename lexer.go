@@ -0,0 +1,239 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// chain operators, see splitCommandChain
+const (
+	chainAlways = ";"
+	chainAnd    = "&&"
+	chainOr     = "||"
+)
+
+// token is one shell-style word of a command line. Value is the word after quote
+// removal and escape processing, Start and End are byte offsets of the raw word
+// in the input, so the raw text is input[Start:End]. Op marks an unquoted chain
+// operator (;, && or ||), Value then holds the operator.
+type token struct {
+	Value string
+	Start int
+	End   int
+	Op    bool
+}
+
+// tokenize splits input into shell-style words. It understands runs of
+// whitespace, single quotes (no escapes inside), double quotes (\" and \\ are
+// escaped inside) and backslash escapes outside of quotes. Adjacent quoted and
+// unquoted parts are joined into one word, so `a"b c"` is the single word `ab c`.
+// Unquoted ;, && and || are returned as operator tokens even without spaces around.
+//
+// When the input ends inside a quote or after a lone backslash an error is
+// returned together with the tokens parsed so far; the last token then holds the
+// partial word, which is what completion wants while the user is still typing.
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	var (
+		word    strings.Builder
+		inWord  bool
+		start   int
+		quote   byte
+		quoteAt int
+	)
+	flush := func(end int) {
+		if inWord {
+			tokens = append(tokens, token{Value: word.String(), Start: start, End: end})
+		}
+		word.Reset()
+		inWord = false
+	}
+	begin := func(index int) {
+		if !inWord {
+			inWord = true
+			start = index
+		}
+	}
+
+	for index := 0; index < len(input); index++ {
+		c := input[index]
+		switch quote {
+		case '\'':
+			if c == '\'' {
+				quote = 0
+				continue
+			}
+			word.WriteByte(c)
+			continue
+		case '"':
+			switch c {
+			case '"':
+				quote = 0
+			case '\\':
+				if index+1 < len(input) && (input[index+1] == '"' || input[index+1] == '\\') {
+					index++
+					word.WriteByte(input[index])
+				} else {
+					word.WriteByte(c)
+				}
+			default:
+				word.WriteByte(c)
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			flush(index)
+		case '\'', '"':
+			begin(index)
+			quote = c
+			quoteAt = index
+		case ';':
+			flush(index)
+			tokens = append(tokens, token{Value: chainAlways, Start: index, End: index + 1, Op: true})
+		case '&', '|':
+			if index+1 < len(input) && input[index+1] == c {
+				flush(index)
+				tokens = append(tokens, token{Value: input[index : index+2], Start: index, End: index + 2, Op: true})
+				index++
+				continue
+			}
+			begin(index)
+			word.WriteByte(c)
+		case '\\':
+			begin(index)
+			if index+1 == len(input) {
+				flush(len(input))
+				return tokens, fmt.Errorf("unexpected end of input after '\\' at position %d", index)
+			}
+			index++
+			word.WriteByte(input[index])
+		default:
+			begin(index)
+			word.WriteByte(c)
+		}
+	}
+	flush(len(input))
+	if quote != 0 {
+		return tokens, fmt.Errorf("unterminated %c quote starting at position %d", quote, quoteAt)
+	}
+	return tokens, nil
+}
+
+// SplitCommandLine splits a command line into arguments the way a shell does,
+// honouring quotes and backslash escapes. Unterminated quotes are reported as an
+// error instead of being silently mis-parsed.
+func SplitCommandLine(input string) ([]string, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	return tokenValues(tokens), nil
+}
+
+func tokenValues(tokens []token) []string {
+	values := make([]string, len(tokens))
+	for index, t := range tokens {
+		values[index] = t.Value
+	}
+	return values
+}
+
+// QuoteArg quotes s so that SplitCommandLine turns it back into a single argument.
+// Strings without special characters are returned unchanged.
+func QuoteArg(s string) string {
+	if s == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(s, " \t\n\r'\"\\;&|") {
+		return s
+	}
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// currentToken returns the index of the token that the cursor at pos belongs to.
+// A cursor directly behind a word still belongs to it; a cursor in whitespace
+// belongs to no token and -1 is returned.
+func currentToken(tokens []token, pos int) int {
+	for index, t := range tokens {
+		if !t.Op && pos >= t.Start && pos <= t.End {
+			return index
+		}
+	}
+	return -1
+}
+
+// isInputtingNewToken reports whether the cursor at the end of input starts a new
+// word, i.e. the input is empty or ends with whitespace outside of quotes.
+func isInputtingNewToken(input string, tokens []token) bool {
+	return len(tokens) == 0 || tokens[len(tokens)-1].End < len(input) || tokens[len(tokens)-1].Op
+}
+
+// commandSegment is one command of a chained command line.
+type commandSegment struct {
+	Tokens []token
+	Op     string // operator before this command, empty for the first one
+}
+
+// Raw returns the text of the command as typed in input.
+func (c commandSegment) Raw(input string) string {
+	if len(c.Tokens) == 0 {
+		return ""
+	}
+	return input[c.Tokens[0].Start:c.Tokens[len(c.Tokens)-1].End]
+}
+
+// splitCommandChain splits tokens at chain operators. Like bash, an operator needs a
+// command before it, so "; a" and "a ; ; b" are errors. A trailing ; is fine, a trailing
+// && or || misses its command.
+func splitCommandChain(tokens []token) ([]commandSegment, error) {
+	segments := []commandSegment{}
+	current := commandSegment{Tokens: []token{}}
+	for _, t := range tokens {
+		if !t.Op {
+			current.Tokens = append(current.Tokens, t)
+			continue
+		}
+		if len(current.Tokens) == 0 {
+			return nil, fmt.Errorf("syntax error near unexpected '%s' at position %d", t.Value, t.Start)
+		}
+		segments = append(segments, current)
+		current = commandSegment{Tokens: []token{}, Op: t.Value}
+	}
+	if len(current.Tokens) > 0 {
+		segments = append(segments, current)
+	} else if current.Op == chainAnd || current.Op == chainOr {
+		return nil, fmt.Errorf("syntax error, missing command after '%s'", current.Op)
+	}
+	return segments, nil
+}
+
+// lastCommand returns the text behind the last chain operator of input, that is
+// the command the cursor at the end of input is typing.
+func lastCommand(input string) string {
+	tokens, _ := tokenize(input)
+	for index := len(tokens) - 1; index >= 0; index-- {
+		if tokens[index].Op {
+			return input[tokens[index].End:]
+		}
+	}
+	return input
+}
+
+// typedWords returns the command the cursor at the end of input is typing, see
+// lastCommand, with its tokens and words. The last word is the one under the cursor,
+// "" if a new word starts, so there is always one. It may be incomplete and doesn't name
+// a sub handler yet. An unterminated quote is fine, it is still being typed.
+func typedWords(input string) (cmd string, tokens []token, words []string) {
+	cmd = lastCommand(input)
+	tokens, _ = tokenize(cmd)
+	words = tokenValues(tokens)
+	if isInputtingNewToken(cmd, tokens) {
+		words = append(words, "")
+	}
+	return cmd, tokens, words
+}
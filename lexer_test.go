@@ -0,0 +1,136 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []token
+		wantErr bool
+	}{
+		{"", []token{}, false},
+		{"   ", []token{}, false},
+		{"a  b", []token{{"a", 0, 1, false}, {"b", 3, 4, false}}, false},
+		{`a "b c" d`, []token{{"a", 0, 1, false}, {"b c", 2, 7, false}, {"d", 8, 9, false}}, false},
+		{`'it''s'`, []token{{"its", 0, 7, false}}, false},
+		{`'a\b'`, []token{{`a\b`, 0, 5, false}}, false},
+		{`a\ b`, []token{{"a b", 0, 4, false}}, false},
+		{`"a\"b\\c\d"`, []token{{`a"b\c\d`, 0, 11, false}}, false},
+		{`a"b c"`, []token{{"ab c", 0, 6, false}}, false},
+		{`""`, []token{{"", 0, 2, false}}, false},
+		{"x;y&&z||w", []token{
+			{"x", 0, 1, false}, {";", 1, 2, true}, {"y", 2, 3, false}, {"&&", 3, 5, true},
+			{"z", 5, 6, false}, {"||", 6, 8, true}, {"w", 8, 9, false},
+		}, false},
+		{"a & b | c", []token{{"a", 0, 1, false}, {"&", 2, 3, false}, {"b", 4, 5, false}, {"|", 6, 7, false}, {"c", 8, 9, false}}, false},
+		{`"a;b" 'c&&d'`, []token{{"a;b", 0, 5, false}, {"c&&d", 6, 12, false}}, false},
+		{`say "open`, []token{{"say", 0, 3, false}, {"open", 4, 9, false}}, true},
+		{`a\`, []token{{"a", 0, 2, false}}, true},
+	}
+	for _, tt := range tests {
+		got, err := tokenize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("tokenize(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestQuoteArg(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"", `""`},
+		{"plain", "plain"},
+		{"a b", "'a b'"},
+		{"a;b", "'a;b'"},
+		{"a&&b", "'a&&b'"},
+		{`a\b`, `'a\b'`},
+		{"it's", `"it's"`},
+		{`it's "x"`, `"it's \"x\""`},
+		{`it's \`, `"it's \\"`},
+	}
+	for _, tt := range tests {
+		got := QuoteArg(tt.arg)
+		if got != tt.want {
+			t.Errorf("QuoteArg(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+		// a quoted arg is split back into the same single arg
+		args, err := SplitCommandLine(got)
+		if err != nil || len(args) != 1 || args[0] != tt.arg {
+			t.Errorf("SplitCommandLine(%s) = %q, %v, want [%q]", got, args, err, tt.arg)
+		}
+	}
+}
+
+func TestSplitCommandChain(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    [][]string // values of the segments, the op of a segment comes first
+		wantErr bool
+	}{
+		{"", [][]string{}, false},
+		{"a b", [][]string{{"", "a", "b"}}, false},
+		{"a; b", [][]string{{"", "a"}, {";", "b"}}, false},
+		{"a && b || c", [][]string{{"", "a"}, {"&&", "b"}, {"||", "c"}}, false},
+		{"a;", [][]string{{"", "a"}}, false},
+		{`a ";" b`, [][]string{{"", "a", ";", "b"}}, false},
+		{"; a", nil, true},
+		{"&& a", nil, true},
+		{"a ; ; b", nil, true},
+		{"a && ; b", nil, true},
+		{"a &&", nil, true},
+		{"a ||", nil, true},
+	}
+	for _, tt := range tests {
+		tokens, _ := tokenize(tt.input)
+		segments, err := splitCommandChain(tokens)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitCommandChain(%q) err = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		got := [][]string{}
+		for _, segment := range segments {
+			got = append(got, append([]string{segment.Op}, tokenValues(segment.Tokens)...))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitCommandChain(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTypedWords(t *testing.T) {
+	tests := []struct {
+		input string
+		cmd   string
+		words []string
+	}{
+		{"", "", []string{""}},
+		{"calc", "calc", []string{"calc"}},
+		{"calc ", "calc ", []string{"calc", ""}},
+		{"calc -a 1", "calc -a 1", []string{"calc", "-a", "1"}},
+		{"ls; calc -a", " calc -a", []string{"calc", "-a"}},
+		{"ls &&", "", []string{""}},
+		{"ls && ", " ", []string{""}},
+		{`say "a b`, `say "a b`, []string{"say", "a b"}},
+		{`say "a;b" c`, `say "a;b" c`, []string{"say", "a;b", "c"}},
+	}
+	for _, tt := range tests {
+		cmd, tokens, words := typedWords(tt.input)
+		if cmd != tt.cmd || !reflect.DeepEqual(words, tt.words) {
+			t.Errorf("typedWords(%q) = %q, %q, want %q, %q", tt.input, cmd, words, tt.cmd, tt.words)
+		}
+		if len(tokens) > len(words) {
+			t.Errorf("typedWords(%q) has %d tokens for %d words", tt.input, len(tokens), len(words))
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// inputViolation is a problem of the cmd line found while typing, Start and End are the
+// byte offsets of the bad token in the line, equal if no token is to blame
+type inputViolation struct {
+	Start, End int
+	Message    string
+}
+
+// validateLine checks the cmd line being typed against the handlers, cursor is the byte
+// offset of the cursor. The word under the cursor may be incomplete, so unknown names
+// are not reported for it.
+func (m *PromptModel) validateLine(line string, cursor int) []inputViolation {
+	violations := []inputViolation{}
+	// an unterminated quote is fine while typing
+	tokens, _ := tokenize(line)
+	if len(tokens) > 0 && tokens[len(tokens)-1].Op {
+		// the next command is not typed yet
+		tokens = tokens[:len(tokens)-1]
+	}
+	segments, err := splitCommandChain(tokens)
+	if err != nil {
+		return append(violations, inputViolation{Message: err.Error()})
+	}
+	for _, segment := range segments {
+		violations = append(violations, m.validateSegment(segment.Tokens, cursor)...)
+	}
+	return violations
+}
+
+// validateSegment checks a single command of the cmd line
+func (m *PromptModel) validateSegment(tokens []token, cursor int) []inputViolation {
+	typing := func(t token) bool {
+		return t.Start <= cursor && cursor <= t.End
+	}
+	handler, depth := m.resolveHandler(tokenValues(tokens))
+	if handler == nil {
+		if typing(tokens[0]) {
+			return nil
+		}
+		return []inputViolation{{tokens[0].Start, tokens[0].End, fmt.Sprintf("can't find handler[%s]", tokens[0].Value)}}
+	}
+	if handler.Handler == nil {
+		if next := depth + 1; next < len(tokens) && !typing(tokens[next]) {
+			return []inputViolation{{tokens[next].Start, tokens[next].End, fmt.Sprintf(
+				"unknown subcommand[%s] of handler[%s]", tokens[next].Value, handler.FullName())}}
+		}
+		return nil
+	}
+	return handler.validateTokens(tokens[depth:], cursor)
+}
+
+// validateTokens checks the args of a cmd of h while it is typed, tokens[0] is the
+// handler name. Handlers with a custom flag set or without flag set are not checked.
+func (h *HandlerInfo) validateTokens(tokens []token, cursor int) []inputViolation {
+	violations := []inputViolation{}
+	if !h.UseFlagSet || h.FlagSetInitFuncImpl != nil {
+		return violations
+	}
+	w := h.newArgWalker()
+	for _, t := range tokens[1:] {
+		typing := t.Start <= cursor && cursor <= t.End
+		report := func(messages ...string) {
+			for _, message := range messages {
+				violations = append(violations, inputViolation{t.Start, t.End, message})
+			}
+		}
+		walked := w.next(t.Value)
+		switch walked.Kind {
+		case inputFlagName:
+			switch {
+			case walked.Err != nil:
+				if !typing {
+					report(walked.Err.Error())
+				}
+			case walked.Suggest < 0:
+				if !typing {
+					name := t.Value
+					if index := strings.Index(name, "="); index >= 0 {
+						name = name[:index]
+					}
+					report(fmt.Sprintf("unknown flag %s", name))
+				}
+			case walked.HasValue && walked.Value != "":
+				report(h.valueViolations(h.Suggests[walked.Suggest], walked.Value, typing)...)
+			}
+		case inputFlagValue:
+			if walked.Value != "" {
+				report(h.valueViolations(h.Suggests[walked.Suggest], walked.Value, typing)...)
+			}
+		case inputPositional:
+			switch {
+			case typing && strings.HasPrefix(walked.Value, "-"):
+				// may still become a flag or a negative number
+			case walked.Suggest >= 0:
+				report(h.valueViolations(h.Suggests[walked.Suggest], walked.Value, typing)...)
+			case h.numVariadic() > 0:
+				elem, _ := h.variadicElem()
+				converter, _ := lookupConverter(elem)
+				report(labelViolations("<"+h.Variadic.Text+"...>", checkValue(&h.Variadic, converter, walked.Value, typing))...)
+			default:
+				report(fmt.Sprintf("too many args, want %d positional args", len(h.positionalSuggests())))
+			}
+		}
+	}
+	return violations
+}
+
+// valueViolations returns the violations of value typed for the param of s, see
+// checkValue
+func (h *HandlerInfo) valueViolations(s Suggest, value string, typing bool) []string {
+	converter, ok := h.paramConverter(s.Text)
+	if !ok {
+		return nil
+	}
+	return labelViolations(h.paramLabel(s), checkValue(&s, converter, value, typing))
+}
+
+// checkValue parse value with converter c and validate it with the validators of s. A
+// value still typing is only parsed, e.g. "pr" is on the way to the enum value "prod".
+func checkValue(s *Suggest, c *Converter, value string, typing bool) []string {
+	v, err := c.Parse(value)
+	if err != nil {
+		return []string{parseError(c, value, err).Error()}
+	}
+	if typing {
+		return nil
+	}
+	return s.validate(c, v)
+}
+
+func labelViolations(label string, violations []string) []string {
+	for index, violation := range violations {
+		violations[index] = label + ": " + violation
+	}
+	return violations
+}
+
+// inputView renders the text input with the tokens of violations highlighted by
+// invalidStyle and the ghost text behind the cursor, like textinput.Model.View does.
+// Lines wider than the input are scrolled by the text input, they are rendered by it
+// without highlight and ghost text.
+func (m *PromptModel) inputView(violations []inputViolation, ghost string) string {
+	value := m.textInput.Value()
+	if (len(violations) == 0 && ghost == "") ||
+		(m.textInput.Width > 0 && lipgloss.Width(value+ghost) > m.textInput.Width) {
+		return m.textInput.View()
+	}
+	runes := []rune(value)
+	invalid := make([]bool, len(runes))
+	for _, violation := range violations {
+		start := utf8.RuneCountInString(value[:violation.Start])
+		end := utf8.RuneCountInString(value[:violation.End])
+		for index := start; index < end; index++ {
+			invalid[index] = true
+		}
+	}
+
+	textStyle := m.textInput.TextStyle.Inline(true)
+	invalidStyle := m.invalidStyle.Inline(true)
+	pos := m.textInput.Position()
+	v := ""
+	for start := 0; start < len(runes); {
+		if start == pos {
+			cursor := m.textInput.Cursor
+			cursor.SetChar(string(runes[pos]))
+			v += cursor.View()
+			start++
+			continue
+		}
+		// render a run of runes with the same style, up to the cursor
+		end := start + 1
+		for end < len(runes) && end != pos && invalid[end] == invalid[start] {
+			end++
+		}
+		if invalid[start] {
+			v += invalidStyle.Render(string(runes[start:end]))
+		} else {
+			v += textStyle.Render(string(runes[start:end]))
+		}
+		start = end
+	}
+	if pos >= len(runes) {
+		cursor := m.textInput.Cursor
+		if ghostRunes := []rune(ghost); len(ghostRunes) > 0 {
+			// the cursor is on the first char of the ghost text
+			cursor.TextStyle = m.ghostStyle
+			cursor.SetChar(string(ghostRunes[0]))
+			v += cursor.View() + m.ghostStyle.Inline(true).Render(string(ghostRunes[1:]))
+		} else {
+			cursor.SetChar(" ")
+			v += cursor.View()
+		}
+	}
+	return m.textInput.PromptStyle.Render(m.textInput.Prompt) + v
+}
+
+// violationView returns the warning line of violations
+func (m *PromptModel) violationView(violations []inputViolation) string {
+	messages := make([]string, len(violations))
+	for index, violation := range violations {
+		messages[index] = violation.Message
+	}
+	return m.errorStyle.Render("! " + strings.Join(messages, "; "))
+}
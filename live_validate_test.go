@@ -0,0 +1,56 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateLine(t *testing.T) {
+	m := newTestModel(t)
+	m.RegisterHandler(func(a, b int16) {}, "calc",
+		WithSuggests([]Suggest{{Text: "a", Min: 10}, {Text: "b"}}))
+	m.RegisterHandler(func(src, dst string) {}, "cp",
+		WithSuggests([]Suggest{{Text: "src", Positional: true}, {Text: "dst", Positional: true}}))
+	m.RegisterHandler(func(nums ...int) {}, "sum", WithVariadicSuggest(Suggest{Text: "nums", Max: 9}))
+	tests := []struct {
+		line   string
+		cursor int // -1 is the end of the line
+		want   []string
+	}{
+		{"calc -", -1, []string{}},
+		{"calc - ", -1, []string{"too many args, want 0 positional args"}},
+		{"calc -x", -1, []string{}},
+		{"calc -x ", -1, []string{"unknown flag -x"}},
+		{"calc -x=1 -a 20", -1, []string{"unknown flag -x"}},
+		{"calc -a 5", -1, []string{}},
+		{"calc -a 5 ", -1, []string{"-a: 5 is less than min 10"}},
+		{"calc -a 5 -b 1", -1, []string{"-a: 5 is less than min 10"}},
+		// parse errors are reported while typing
+		{"calc -a 99999", -1, []string{`-a: invalid value "99999": out of range of int16`}},
+		{"calc -a 20 x", -1, []string{"too many args, want 0 positional args"}},
+		{"cp a b -", -1, []string{}},
+		{"cp a b c", -1, []string{"too many args, want 2 positional args"}},
+		{"cp a b c", 3, []string{"too many args, want 2 positional args"}},
+		{"sum 1 -", -1, []string{}},
+		{"sum 1 x", -1, []string{`<nums...>: invalid value "x": strconv.ParseInt: parsing "x": invalid syntax`}},
+		{"sum 10", -1, []string{}},
+		{"sum 10 ", -1, []string{"<nums...>: 10 is greater than max 9"}},
+		{"nop", -1, []string{}},
+		{"nop x", -1, []string{"can't find handler[nop]"}},
+		{"cp a; calc -", -1, []string{}},
+		{"calc -; cp a", -1, []string{"too many args, want 0 positional args"}},
+	}
+	for _, tt := range tests {
+		cursor := tt.cursor
+		if cursor < 0 {
+			cursor = len(tt.line)
+		}
+		got := []string{}
+		for _, violation := range m.validateLine(tt.line, cursor) {
+			got = append(got, violation.Message)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("validateLine(%q, %d) = %q, want %q", tt.line, cursor, got, tt.want)
+		}
+	}
+}
@@ -67,6 +67,103 @@ func WithBaseStyle(style lipgloss.Style) PromptModelOption {
 	}
 }
 
+// WithFrecencyRanking enable or disable ranking the handler and flag suggests by how
+// often and how recently they are used in history, it is enabled by default
+func WithFrecencyRanking(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.frecencyRanking = enable
+	}
+}
+
+// WithHistoryValues enable or disable suggesting the values of params used in history,
+// e.g. the hosts of "connect -host", it is enabled by default
+func WithHistoryValues(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.historyValues = enable
+	}
+}
+
+// WithHistoryPrefixSearch enable or disable filtering the history walked by up and down
+// with the line before the cursor, e.g. up on "deploy -env" only shows the cmds starting
+// with "deploy -env". It is disabled by default.
+func WithHistoryPrefixSearch(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.historyPrefixSearch = enable
+	}
+}
+
+// WithCommonPrefixCompletion enable or disable completing like bash: the first tab
+// inserts the longest common prefix of the suggests, or the suggest if only one matches,
+// and the next tabs cycle through the suggests. It is disabled by default.
+func WithCommonPrefixCompletion(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.commonPrefixCompletion = enable
+	}
+}
+
+// WithGhostText enable or disable the ghost text, the likely completion of the line
+// shown dim behind the cursor and accepted by right or ctrl+f, alt+f accepts a word.
+// It is enabled by default.
+func WithGhostText(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.ghostTextEnabled = enable
+	}
+}
+
+// WithGhostStyle set the style of the ghost text
+func WithGhostStyle(style lipgloss.Style) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.ghostStyle = style
+	}
+}
+
+// WithSignatureHelp enable or disable the usage line of the handler typed, like
+// "calc [-a int16=10000] [-b int16]", shown below the input. It is enabled by default.
+func WithSignatureHelp(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.signatureHelp = enable
+	}
+}
+
+// WithSignatureStyles set the styles of the usage line, of the param under the cursor
+// and of the params already supplied
+func WithSignatureStyles(style, current, supplied lipgloss.Style) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.signatureStyle = style
+		pm.signatureCurrentStyle = current
+		pm.signatureSuppliedStyle = supplied
+	}
+}
+
+// WithMatchStyle set the style of the chars of a suggest matched by the typed word
+func WithMatchStyle(style lipgloss.Style) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.matchStyle = style
+	}
+}
+
+// WithErrorStyle set the style of the error lines printed when a cmd fails
+func WithErrorStyle(style lipgloss.Style) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.errorStyle = style
+	}
+}
+
+// WithLiveValidation enable or disable validating the cmd line while typing, it is
+// enabled by default
+func WithLiveValidation(enable bool) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.liveValidation = enable
+	}
+}
+
+// WithInvalidStyle set the style of the invalid tokens found by live validation
+func WithInvalidStyle(style lipgloss.Style) PromptModelOption {
+	return func(pm *PromptModel) {
+		pm.invalidStyle = style
+	}
+}
+
 func WithIgnoreEmptyCmd(ignore bool) PromptModelOption {
 	return func(pm *PromptModel) {
 		pm.ignoreEmptyCmd = ignore
@@ -226,6 +323,9 @@ func loadHistory(m *PromptModel) error {
 		}
 		m.historyBuffers = append(m.historyBuffers, h[len(timeFormat)+spaceLen:])
 		m.historys = append(m.historys, h[len(timeFormat)+spaceLen:])
+		// the time is written in local time, see RunCmdMsg
+		at, _ := time.ParseInLocation(timeFormat, h[:len(timeFormat)], time.Local)
+		m.recordUse(h[len(timeFormat)+spaceLen:], at)
 	}
 
 	m.historyBuffers = append(m.historyBuffers, "")
@@ -291,6 +391,16 @@ func WithGetSuggestMethod(f GetSuggestFunc) HandlerInfoOption {
 	}
 }
 
+// WithAsyncGetSuggestMethod set a slow suggest provider, e.g. one querying a database.
+// It runs outside of the ui after the input is unchanged for debounce, a loading row
+// is shown meanwhile and stale requests are canceled.
+func WithAsyncGetSuggestMethod(f AsyncGetSuggestFunc, debounce time.Duration) HandlerInfoOption {
+	return func(h *HandlerInfo) {
+		h.AsyncGetSuggestMethod = f
+		h.SuggestDebounce = debounce
+	}
+}
+
 func WithHandlerHelpMsg(helpMsg string) HandlerInfoOption {
 	return func(h *HandlerInfo) {
 		h.HelpMsg = helpMsg
@@ -308,3 +418,38 @@ func WithFlagSetInitFunc(f FlagSetInitFunc) HandlerInfoOption {
 		h.FlagSetInitFuncImpl = f
 	}
 }
+
+// WithSubHandlers add child commands to the handler, e.g. "user add" and "user del"
+// are the sub handlers add and del of the handler user.
+func WithSubHandlers(subHandlers ...*HandlerInfo) HandlerInfoOption {
+	return func(h *HandlerInfo) {
+		if h.SubHandlers == nil {
+			h.SubHandlers = map[string]*HandlerInfo{}
+		}
+		for _, subHandler := range subHandlers {
+			if _, ok := h.SubHandlers[subHandler.Name]; ok {
+				panic(fmt.Errorf("sub handler[%s] of handler[%s] has been registered", subHandler.Name, h.Name))
+			}
+			subHandler.parent = h
+			h.SubHandlers[subHandler.Name] = subHandler
+		}
+	}
+}
+
+// WithGNUFlags parse the flags of the handler GNU style: long flags like --verbose and
+// --name=value, short flags like -v which can be combined like -vq or -n5. The suggest
+// prefix becomes "--".
+func WithGNUFlags() HandlerInfoOption {
+	return func(h *HandlerInfo) {
+		h.GNUFlags = true
+		h.SuggestPrefix = gnuLongPrefix
+	}
+}
+
+// WithVariadicSuggest describe the variadic param of the handler, Text is its name in
+// hints and Description is shown in suggests
+func WithVariadicSuggest(s Suggest) HandlerInfoOption {
+	return func(h *HandlerInfo) {
+		h.Variadic = s
+	}
+}
@@ -0,0 +1,77 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Path is a file path param, "~" is expanded to the home dir and its value is completed
+// by CompletePath
+type Path string
+
+func init() {
+	RegisterConverter(Path(""), Converter{
+		Parse: func(s string) (interface{}, error) {
+			return Path(expandHome(s)), nil
+		},
+		Complete: CompletePath,
+	})
+}
+
+const pathSeparators = "/" + string(filepath.Separator)
+
+// expandHome replace the leading "~" of path with the home dir of the user
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
+
+// CompletePath is a CompleteFunc listing the entries of the dir typed in input whose name
+// starts with the rest of input, e.g. "src/ma" lists "src/main.go". Directories end with
+// "/" and are Partial, so Tab on a single one completes on into it. Hidden entries are
+// listed only if the name typed starts with ".". It can be used as ValueCompleter of a
+// Suggest, params of type Path use it by default.
+func CompletePath(input string) []Suggest {
+	if input == "~" {
+		return []Suggest{{Text: "~/", Description: "home dir", Partial: true}}
+	}
+	dir, base := "", input
+	if index := strings.LastIndexAny(input, pathSeparators); index >= 0 {
+		dir, base = input[:index+1], input[index+1:]
+	}
+	readDir := expandHome(dir)
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return []Suggest{}
+	}
+	suggests := []Suggest{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+			continue
+		}
+		s := Suggest{Text: dir + name, Description: "file"}
+		// follow symlinks, a link to a dir is completed as dir
+		if info, err := os.Stat(filepath.Join(readDir, name)); err == nil && info.IsDir() {
+			s.Text += "/"
+			s.Description = "dir"
+			s.Partial = true
+		}
+		suggests = append(suggests, s)
+	}
+	sort.Slice(suggests, func(i, j int) bool {
+		return suggests[i].Text < suggests[j].Text
+	})
+	return suggests
+}
@@ -0,0 +1,64 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCompletePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.Mkdir(filepath.Join(home, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"main.go", ".hidden", filepath.Join("src", "lib.go")} {
+		if err := os.WriteFile(filepath.Join(home, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(home, "src"), filepath.Join(home, "link")); err != nil {
+		t.Fatal(err)
+	}
+	file := func(text string) Suggest { return Suggest{Text: text, Description: "file"} }
+	dir := func(text string) Suggest { return Suggest{Text: text, Description: "dir", Partial: true} }
+	tests := []struct {
+		input string
+		want  []Suggest
+	}{
+		{"~", []Suggest{{Text: "~/", Description: "home dir", Partial: true}}},
+		{"~/", []Suggest{dir("~/link/"), file("~/main.go"), dir("~/src/")}},
+		{"~/m", []Suggest{file("~/main.go")}},
+		{"~/.", []Suggest{file("~/.hidden")}},
+		{"~/s", []Suggest{dir("~/src/")}},
+		{"~/src/", []Suggest{file("~/src/lib.go")}},
+		{"~/link/l", []Suggest{file("~/link/lib.go")}},
+		{"~/x", []Suggest{}},
+		{"~/nope/", []Suggest{}},
+		{home + "/ma", []Suggest{file(home + "/main.go")}},
+	}
+	for _, tt := range tests {
+		if got := CompletePath(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("CompletePath(%q) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	tests := []struct {
+		path, want string
+	}{
+		{"~", "/home/u"},
+		{"~/a", "/home/u/a"},
+		{"~a", "~a"},
+		{"a/~", "a/~"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := expandHome(tt.path); got != tt.want {
+			t.Errorf("expandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
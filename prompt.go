@@ -1,7 +1,10 @@
 package prompt
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +25,10 @@ type PromptModel struct {
 	historyIndex    int
 
 	historyBufferPos int
+
+	historyPrefixSearch bool   // up and down only walk the entries starting with historyPrefix
+	historyPrefix       string // the line before the cursor when walking history began
+	historyPrefixLine   string // the line shown by the last walk, see historyPrefixIndex
 	// historyOuts    []string // save history out info
 
 	printCmd       bool
@@ -33,6 +40,11 @@ type PromptModel struct {
 	suggestIndex  int
 	suggestNum    int
 
+	suggestedInput string             // the input matchSuggests are for
+	suggestsValid  bool               // matchSuggests are computed for suggestedInput
+	cancelSuggest  context.CancelFunc // cancel the running async suggest request
+	suggestSeq     int                // seq of the latest async suggest request
+
 	initCmds       []tea.Cmd
 	programOptions []tea.ProgramOption
 
@@ -41,8 +53,37 @@ type PromptModel struct {
 	forceStyle lipgloss.Style
 	baseStyle  lipgloss.Style
 
-	runCmdMark  bool
-	runCmdDeply int64 // ms
+	runCmdMark   bool
+	runCmdDeply  int64              // ms
+	cancelRunCmd context.CancelFunc // cancel the running cmd, ctrl+c
+	lastErr      error              // status of the last cmd line
+
+	errorStyle lipgloss.Style
+
+	liveValidation bool           // validate the cmd line while typing
+	invalidStyle   lipgloss.Style // style of the invalid tokens
+
+	matchStyle lipgloss.Style // style of the chars of a suggest matched by the typed word
+
+	frecencyRanking bool                // rank suggests by the frecency of history uses
+	historyUses     []historyUse        // every cmd line loaded from history or run
+	frecencyScores  map[string]float64  // nil if not built yet, see frecencyScore
+	paramHistory    map[string][]string // values of the params in history, see paramHistoryValues
+	historyValues   bool                // suggest the values of params used in history
+
+	ghostTextEnabled bool           // show the ghost text, see ghostText
+	ghostStyle       lipgloss.Style // style of the ghost text
+
+	commonPrefixCompletion bool   // the first tab completes the common prefix of the suggests
+	completedLine          string // the line the last tab completed, see completeCommonPrefix
+
+	signatureHelp          bool           // show the usage of the handler typed, see signatureView
+	signatureStyle         lipgloss.Style // style of the usage line
+	signatureCurrentStyle  lipgloss.Style // style of the param under the cursor
+	signatureSuppliedStyle lipgloss.Style // style of the params already supplied
+
+	search          *historySearch // the history search, nil if not searching
+	lastSearchQuery string         // ctrl+r with an empty query searches it again
 
 	outFile     string
 	filterAscii bool // filter invisible characters in ascii
@@ -82,6 +123,21 @@ func NewPromptModel(opts ...PromptModelOption) *PromptModel {
 		suggestNum:     defaultSuggestNum,
 		forceStyle:     defaultForceStyle,
 		baseStyle:      defaultBaseStyle,
+		errorStyle:     defaultErrorStyle,
+		invalidStyle:   defaultInvalidStyle,
+		matchStyle:     defaultMatchStyle,
+		liveValidation: true,
+
+		frecencyRanking: true,
+		historyValues:   true,
+
+		ghostTextEnabled: true,
+		ghostStyle:       defaultGhostStyle,
+
+		signatureHelp:          true,
+		signatureStyle:         defaultSignatureStyle,
+		signatureCurrentStyle:  defaultSignatureCurrentStyle,
+		signatureSuppliedStyle: defaultSignatureSuppliedStyle,
 
 		runCmdDeply: defaultRunCmdDeply,
 		printCmd:    defaultPrintCmd,
@@ -130,57 +186,181 @@ func (m *PromptModel) RegisterHandlerInfos(handlerInfos ...*HandlerInfo) {
 		if err := handlerInfo.CheckAndInitHandler(); err != nil {
 			panic(err)
 		}
-		if handlerInfo.Callback == nil {
-			handlerInfo.Callback = m.defaultCallback
-		}
+		setDefaultCallback(handlerInfo, m.defaultCallback)
 		if _, ok := m.handlerInfos[handlerInfo.Name]; ok {
 			panic(fmt.Errorf("handler[%s] has been registered", handlerInfo.Name))
 		}
 		m.handlerInfos[handlerInfo.Name] = handlerInfo
 	}
+	// the scores and values only count registered handlers, rebuild them
+	m.frecencyScores = nil
+	m.paramHistory = nil
 }
 
-func (m *PromptModel) runCmd(cmd string) tea.Cmd {
+func setDefaultCallback(h *HandlerInfo, callback HandlerCallback) {
+	if h.Callback == nil {
+		h.Callback = callback
+	}
+	for _, subHandler := range h.SubHandlers {
+		setDefaultCallback(subHandler, callback)
+	}
+}
+
+// cmdResult is the result of a cmd line
+type cmdResult struct {
+	errLines []string // styled error lines to print
+	err      error    // status of the last command that ran
+	exit     bool     // a handler with ExitAfterRun ran
+}
+
+// runCmd runs every command of the cmd line, it is called outside of the ui goroutine.
+func (m *PromptModel) runCmd(ctx context.Context, cmd string) cmdResult {
+	result := cmdResult{}
 	if len(strings.ReplaceAll(cmd, " ", "")) == 0 {
-		return nil
+		return result
 	}
-	cmdWithTime := fmt.Sprintf("%s: %s", time.Now().Local().Format(timeFormat), cmd)
-	if m.readyToSaveHistory {
-		m.historyChan <- cmdWithTime + "\n"
+	fail := func(format string, a ...interface{}) {
+		result.errLines = append(result.errLines, m.errorStyle.Render(fmt.Sprintf(format, a...)))
 	}
 
-	handlerName := strings.Split(cmd, " ")[0]
-	handler, ok := m.handlerInfos[handlerName]
-	if !ok {
-		return tea.Printf("can't find handler[%s]", handlerName)
+	tokens, err := tokenize(cmd)
+	if err == nil {
+		var segments []commandSegment
+		if segments, err = splitCommandChain(tokens); err == nil {
+			m.runSegments(ctx, cmd, segments, &result, fail)
+			return result
+		}
 	}
-	if err := handler.Run(cmd); err != nil {
-		return tea.Printf("run cmd of handler[%s] fail, err: %v", handlerName, err)
+	result.err = err
+	fail("can't parse cmd, err: %v", err)
+	return result
+}
+
+func (m *PromptModel) runSegments(ctx context.Context, cmd string, segments []commandSegment,
+	result *cmdResult, fail func(format string, a ...interface{})) {
+	for _, segment := range segments {
+		if ctx.Err() != nil {
+			// canceled, don't start the rest of the chain
+			return
+		}
+		// short-circuit like a shell, the status of a skipped command is the one before
+		if (segment.Op == chainAnd && result.err != nil) || (segment.Op == chainOr && result.err == nil) {
+			continue
+		}
+		// the top handler dispatches to the sub handler, which is checked for the exit
+		handler, _ := m.resolveHandler(tokenValues(segment.Tokens))
+		if handler == nil {
+			result.err = fmt.Errorf("can't find handler[%s]", segment.Tokens[0].Value)
+			fail("%v", result.err)
+			continue
+		}
+		top := m.handlerInfos[segment.Tokens[0].Value]
+		if result.err = top.RunContext(ctx, segment.Raw(cmd)); result.err != nil {
+			var handlerErr *HandlerError
+			var paramErr *ParamError
+			if errors.As(result.err, &handlerErr) {
+				fail("%v", handlerErr)
+			} else if errors.As(result.err, &paramErr) {
+				// one line per violation
+				fail("handler[%s] invalid params:", paramErr.Name)
+				for _, violation := range paramErr.Violations {
+					fail("  %s", violation)
+				}
+			} else {
+				fail("run cmd of handler[%s] fail, err: %v", handler.FullName(), result.err)
+			}
+			continue
+		}
+		if handler.ExitAfterRun {
+			result.exit = true
+			return
+		}
+	}
+}
+
+// Exec runs a cmd line without the interactive ui, e.g. from a script. Errors are
+// printed to stdout and the status of the last command that ran is returned, so
+// callers can stop a script on failure.
+func (m *PromptModel) Exec(cmd string) error {
+	result := m.runCmd(context.Background(), cmd)
+	for _, line := range result.errLines {
+		fmt.Println(line)
 	}
-	if handler.ExitAfterRun {
+	if result.exit {
 		m.exit = true
-		return tea.Quit
 	}
-	return nil
+	return result.err
+}
+
+// LastError returns the status of the last cmd line run in the prompt, nil means success.
+func (m *PromptModel) LastError() error {
+	return m.lastErr
 }
 
 func (m *PromptModel) Init() tea.Cmd {
-	return tea.Batch(m.initCmds...)
+	return tea.Batch(append(m.initCmds, m.refreshSuggests())...)
 }
 
 func (m *PromptModel) getCurrentCmdString() string {
-	return strings.Join(strings.Fields(m.historyBuffers[m.historyIndex]), " ")
+	// only trim the edges, spaces between words may be quoted and must be kept
+	return strings.TrimSpace(m.historyBuffers[m.historyIndex])
 }
 
 func (m *PromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	if m.runCmdMark {
+		// the running cmd resets the params of its handler, suggests would read them
+		return model, cmd
+	}
+	// suggests are updated here instead of in View, so a slow provider can't block rendering
+	if suggestCmd := m.refreshSuggests(); suggestCmd != nil {
+		cmd = tea.Batch(cmd, suggestCmd)
+	}
+	return model, cmd
+}
+
+// refreshSuggests updates the suggest list if the input before the cursor changed
+func (m *PromptModel) refreshSuggests() tea.Cmd {
+	input := m.historyBuffers[m.historyIndex][:m.historyBufferPos]
+	if m.suggestsValid && input == m.suggestedInput {
+		return nil
+	}
+	m.suggestedInput, m.suggestsValid = input, true
+	return m.updateSuggentList()
+}
+
+// isRunningKey reports whether keypress is handled while a cmd runs
+func isRunningKey(keypress string) bool {
+	switch keypress {
+	case "ctrl+c", "ctrl+d", "ctrl+l":
+		return true
+	}
+	return false
+}
+
+func (m *PromptModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd = nil
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.search != nil {
+			return m.updateSearch(msg)
+		}
+		if m.runCmdMark && !isRunningKey(msg.String()) {
+			// the line can't be edited while a cmd runs
+			return m, nil
+		}
 		switch keypress := msg.String(); keypress {
 		case "ctrl+d":
 			m.exit = true
 			return m, tea.Quit
 		case "ctrl+c":
+			if m.runCmdMark {
+				// cancel the running cmd, the prompt comes back with runCmdDoneMsg
+				if m.cancelRunCmd != nil {
+					m.cancelRunCmd()
+				}
+				return m, nil
+			}
 			m.historyBuffers[m.historyIndex] = ""
 			m.textInput.SetValue("")
 			m.historyBufferPos = m.textInput.Position()
@@ -197,6 +377,7 @@ func (m *PromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.historyIndex = len(m.historys)
 			m.historyBuffers = make([]string, len(m.historys)+1)
 			copy(m.historyBuffers, m.historys)
+			m.historyPrefix, m.historyPrefixLine = "", ""
 			if m.printCmd {
 				// 覆盖刷新
 				fmt.Println(m.prefix + cmdString)
@@ -207,23 +388,48 @@ func (m *PromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.historyBufferPos = m.textInput.Position()
 			m.suggestIndex = -1
 			m.runCmdMark = true
+			// drop the pending suggests, the handler may be running
+			m.cancelSuggestRequest()
 			return m, runCmd(cmdString)
+		case "ctrl+r", "ctrl+s":
+			m.startSearch(keypress == "ctrl+r")
+			return m, nil
+		case "right", "ctrl+f", "alt+f":
+			// accept the ghost text, otherwise move the cursor like the text input does
+			if m.acceptGhostText(keypress == "alt+f") {
+				m.suggestIndex = -1
+				return m, nil
+			}
+			m.textInput, cmd = m.textInput.Update(msg)
+			m.historyBuffers[m.historyIndex] = m.textInput.Value()
+			m.historyBufferPos = m.textInput.Position()
+			return m, cmd
 		case "up", "down", "ctrl+p", "ctrl+n":
-			switch msg.String() {
-			case "up", "ctrl+p":
+			up := keypress == "up" || keypress == "ctrl+p"
+			switch {
+			case m.historyPrefixSearch:
+				m.historyIndex = m.historyPrefixIndex(up)
+			case up:
 				m.historyIndex = max(0, m.historyIndex-1)
-			case "down", "ctrl+n":
+			default:
 				m.historyIndex = min(m.historyIndex+1, len(m.historys))
 			}
 			m.textInput.SetValue(m.historyBuffers[m.historyIndex])
 			m.textInput.SetCursor(len(m.historyBuffers[m.historyIndex]))
 			m.historyBufferPos = m.textInput.Position()
+			m.historyPrefixLine = m.textInput.Value()
 			m.suggestIndex = -1
 			return m, nil
 		case "tab", "shift+tab":
 			if len(m.matchSuggests) == 0 {
 				return m, nil
 			}
+			if keypress == "tab" && m.commonPrefixCompletion && m.suggestIndex < 0 &&
+				m.historyBuffers[m.historyIndex] != m.completedLine {
+				// the first tab completes the common prefix, the next ones cycle
+				m.completeCommonPrefix()
+				return m, nil
+			}
 			switch keypress {
 			case "tab":
 				m.suggestIndex = (m.suggestIndex + 1) % len(m.matchSuggests)
@@ -235,13 +441,27 @@ func (m *PromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 			// choise suggest, flush text input buffer
-			newCmd, _ := replaceScope(m.historyBuffers[m.historyIndex],
-				m.matchSuggests[m.suggestIndex].Text, m.historyBufferPos)
+			suggest := m.matchSuggests[m.suggestIndex]
+			if isHintSuggest(suggest) {
+				// only a hint, nothing to insert
+				return m, nil
+			}
+			text := suggest.Text
+			if suggest.SuggestType == SuggestOfValue {
+				text = QuoteArg(text)
+			}
+			newCmd, newPos := replaceScope(m.historyBuffers[m.historyIndex], text, m.historyBufferPos)
 			if m.historyBuffers[m.historyIndex] == m.textInput.Value() {
 				m.historyBufferPos = m.textInput.Position()
 			}
 			m.textInput.SetValue(newCmd)
-			m.textInput.SetCursor(len(newCmd))
+			m.textInput.SetCursor(newPos)
+			if suggest.Partial && m.numInsertableSuggests() == 1 {
+				// accept the partial value, the next tab completes behind it, e.g. in a dir
+				m.historyBuffers[m.historyIndex] = newCmd
+				m.historyBufferPos = m.textInput.Position()
+				m.suggestIndex = -1
+			}
 			return m, nil
 		default:
 			// input any key, need make sure text input buffer and current buffer is same
@@ -254,13 +474,51 @@ func (m *PromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case RunCmdMsg:
 		cmdWithTime := fmt.Sprintf("%s: %s", time.Now().Local().Format(timeFormat), msg.cmd)
+		if m.readyToSaveHistory && len(strings.ReplaceAll(msg.cmd, " ", "")) > 0 {
+			m.historyChan <- cmdWithTime + "\n"
+		}
+		if len(strings.ReplaceAll(msg.cmd, " ", "")) > 0 {
+			m.recordUse(msg.cmd, time.Now())
+		}
 		if !m.printCmd && m.printRunTime {
 			fmt.Println(cmdWithTime)
 		}
-		time.Sleep(time.Duration(m.runCmdDeply * int64(time.Millisecond)))
-		cmd := m.runCmd(msg.cmd)
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancelRunCmd = cancel
+		// run cmd outside of the ui goroutine, so ctrl+c can cancel it
+		return m, func() tea.Msg {
+			time.Sleep(time.Duration(m.runCmdDeply * int64(time.Millisecond)))
+			return runCmdDoneMsg{result: m.runCmd(ctx, msg.cmd)}
+		}
+	case runCmdDoneMsg:
+		if m.cancelRunCmd != nil {
+			m.cancelRunCmd()
+			m.cancelRunCmd = nil
+		}
 		m.runCmdMark = false
-		return m, cmd
+		m.lastErr = msg.result.err
+		outs := []tea.Cmd{}
+		for _, line := range msg.result.errLines {
+			outs = append(outs, tea.Println(line))
+		}
+		if msg.result.exit {
+			m.exit = true
+			outs = append(outs, tea.Quit)
+		}
+		return m, tea.Sequence(outs...)
+	case asyncSuggestMsg:
+		if msg.seq != m.suggestSeq {
+			// the input changed after the request
+			return m, nil
+		}
+		// release the context of the finished request
+		if m.cancelSuggest != nil {
+			m.cancelSuggest()
+			m.cancelSuggest = nil
+		}
+		m.matchSuggests = rankSuggests(typedWord(m.suggestedInput), msg.suggests, m.frecencyBonus(msg.handler))
+		m.suggestIndex = -1
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.textInput.Width = msg.Width - len(m.prefix) - 1 // 防止显示不全。 -1是为了显示force光标
 		return m, nil
@@ -272,8 +530,26 @@ func (m *PromptModel) View() string {
 	if m.runCmdMark || m.exit {
 		return m.prefix
 	}
-	m.updateSuggentList()
-	s := m.textInput.View()
+	if m.search != nil {
+		s := m.searchView()
+		if m.withHelpMsg {
+			s += "\n" + HelpView()
+		}
+		return s
+	}
+	violations := []inputViolation{}
+	if m.liveValidation {
+		value := m.textInput.Value()
+		cursor := len(string([]rune(value)[:m.textInput.Position()]))
+		violations = m.validateLine(value, cursor)
+	}
+	s := m.inputView(violations, m.ghostText())
+	if len(violations) > 0 {
+		s += "\n" + m.violationView(violations)
+	}
+	if signature := m.signatureView(); signature != "" {
+		s += "\n" + signature
+	}
 	if m.SuggestView() != "" {
 		s += "\n" + m.SuggestView()
 	}
@@ -300,22 +576,58 @@ func (m *PromptModel) SuggestView() string {
 	m.baseStyle = m.baseStyle.Width(width)
 	for index, suggestView := range suggestViews {
 		if index == forceSuggestIndex {
-			suggestViews[index] = m.forceStyle.Render(suggestView)
+			suggestViews[index] = m.renderSuggest(m.matchSuggests[start+index], suggestView, m.forceStyle)
 		} else {
-			suggestViews[index] = m.baseStyle.Render(suggestView)
+			suggestViews[index] = m.renderSuggest(m.matchSuggests[start+index], suggestView, m.baseStyle)
 		}
 	}
 	return strings.Join(suggestViews, "\n")
 }
 
+// renderSuggest renders the view of suggest s with style, the chars of the text matched
+// by the typed word are rendered with matchStyle on top of style
+func (m *PromptModel) renderSuggest(s Suggest, view string, style lipgloss.Style) string {
+	if len(s.matched) == 0 {
+		return style.Render(view)
+	}
+	// the view starts with the text, see getSuggestView
+	matched := map[int]bool{}
+	for _, index := range s.matched {
+		matched[index] = true
+	}
+	inline := style.Copy().UnsetWidth()
+	highlight := m.matchStyle.Copy().Inherit(inline)
+	runes := []rune(view)
+	out := ""
+	for start := 0; start < len(runes); {
+		end := start + 1
+		for end < len(runes) && matched[end] == matched[start] {
+			end++
+		}
+		if matched[start] {
+			out += highlight.Render(string(runes[start:end]))
+		} else {
+			out += inline.Render(string(runes[start:end]))
+		}
+		start = end
+	}
+	if padding := style.GetWidth() - lipgloss.Width(view); padding > 0 {
+		out += inline.Render(strings.Repeat(" ", padding))
+	}
+	return out
+}
+
 func getSuggestView(s Suggest) string {
-	if s.SuggestType == SuggestOfHandler {
+	if s.SuggestType != SuggestOfParam {
 		if s.Description != "" {
 			return fmt.Sprintf("%s: %s", s.Text, s.Description)
 		}
 		return s.Text
 	}
-	return fmt.Sprintf("%s, default: %v, description: %s", s.Text, s.Default, s.Description)
+	if reflect.ValueOf(s.Default).Kind() == reflect.Ptr && isNil(s.Default) {
+		return fmt.Sprintf("%s, optional, unset, description: %s", s.Text, s.Description)
+	}
+	return fmt.Sprintf("%s, default: %s, description: %s", s.Text, formatValue(s.Default), s.Description)
 }
 
 func (m *Prompt) Run() error {
@@ -323,21 +635,26 @@ func (m *Prompt) Run() error {
 	return err
 }
 
+// replaceScope replace the word under pos with newString, if pos is between two words
+// newString is inserted at pos. It returns the new cmd and the position behind newString.
 func replaceScope(cmdString, newString string, pos int) (string, int) {
-	subIndex := 0
-	for index := 0; index < pos; index++ {
-		if cmdString[index] == ' ' {
-			subIndex++
-		}
+	tokens, _ := tokenize(cmdString)
+	start, end := pos, pos
+	if index := currentToken(tokens, pos); index >= 0 {
+		start, end = tokens[index].Start, tokens[index].End
 	}
-	cmds := strings.Split(cmdString, " ")
-	cmds[subIndex] = newString
-	newPos := 0
-	for index := 0; index < subIndex; index++ {
-		newPos += len(cmds[index]) + 1
+	return cmdString[:start] + newString + cmdString[end:], start + len(newString)
+}
+
+// numInsertableSuggests returns the number of suggests inserted by tab, hints excluded
+func (m *PromptModel) numInsertableSuggests() int {
+	num := 0
+	for _, s := range m.matchSuggests {
+		if !isHintSuggest(s) {
+			num++
+		}
 	}
-	newPos += len(cmds[subIndex])
-	return strings.Join(cmds, " "), newPos
+	return num
 }
 
 // getSuggestScope >= start; < end
@@ -360,55 +677,108 @@ func (m *PromptModel) SetHandlers(handlers map[string]*HandlerInfo) {
 	m.handlerInfos = handlers
 }
 
-func (m *PromptModel) updateSuggentList() {
+// updateSuggentList updates the suggest list for the input before the cursor. The
+// suggests of a handler with AsyncGetSuggestMethod are requested by the returned cmd, a
+// loading row is shown meanwhile.
+func (m *PromptModel) updateSuggentList() tea.Cmd {
+	m.cancelSuggestRequest()
 	cmd := m.historyBuffers[m.historyIndex][:m.historyBufferPos]
 	if m.ignoreEmptyCmd && cmd == "" {
 		m.matchSuggests = make([]Suggest, 0)
-		return
-	}
-	cmds := strings.Split(cmd, " ")
-	if len(cmds) == 0 {
-		cmds = append(cmds, "")
+		return nil
 	}
-	handler, ok := m.handlerInfos[cmds[0]]
-	if !ok || len(cmds) == 1 {
-		m.genHandlerSuggests(cmds[0])
+	cmd, tokens, cmds := typedWords(cmd)
+	handler, depth := m.resolveHandler(cmds[:len(cmds)-1])
+	if handler == nil {
+		// top handlers are ranked without parent
+		m.matchSuggests = genHandlerSuggests(cmds[0], m.handlerInfos)
 	} else {
-		var matchSuggests []Suggest
-		var err error
-		var getHandlerSuggests GetSuggestFunc = DefaultGetHandlerSuggests
-		if handler.GetSuggestMethod != nil {
-			getHandlerSuggests = handler.GetSuggestMethod
-		}
-		matchSuggests, err = getHandlerSuggests(handler, m.historyBuffers[m.historyIndex][:m.historyBufferPos])
-		if err != nil || matchSuggests == nil {
-			m.matchSuggests = make([]Suggest, 0)
+		subSuggests := []Suggest{}
+		if depth+2 == len(cmds) {
+			subSuggests = genHandlerSuggests(cmds[depth+1], handler.SubHandlers)
+		}
+		if handler.Handler == nil {
+			m.matchSuggests = subSuggests
+		} else if handler.AsyncGetSuggestMethod != nil {
+			knownSuggests := m.historyValueSuggests(handler, cmd[tokens[depth].Start:], subSuggests)
+			m.matchSuggests = append(rankSuggests(cmds[len(cmds)-1], knownSuggests, m.frecencyBonus(handler)), loadingSuggest)
 			m.suggestIndex = -1
-			return
+			return m.requestSuggests(handler, cmd[tokens[depth].Start:], knownSuggests)
+		} else {
+			var matchSuggests []Suggest
+			var err error
+			var getHandlerSuggests GetSuggestFunc = DefaultGetHandlerSuggests
+			if handler.GetSuggestMethod != nil {
+				getHandlerSuggests = handler.GetSuggestMethod
+			}
+			matchSuggests, err = getHandlerSuggests(handler, cmd[tokens[depth].Start:])
+			if err != nil || matchSuggests == nil {
+				m.matchSuggests = make([]Suggest, 0)
+				m.suggestIndex = -1
+				return nil
+			}
+			matchSuggests = m.historyValueSuggests(handler, cmd[tokens[depth].Start:], matchSuggests)
+			m.matchSuggests = append(subSuggests, matchSuggests...)
 		}
-		m.matchSuggests = matchSuggests
 	}
 
-	m.matchSuggests = SortSuggest(m.matchSuggests)
+	m.matchSuggests = rankSuggests(cmds[len(cmds)-1], m.matchSuggests, m.frecencyBonus(handler))
+	return nil
+}
+
+// typedWord returns the word typed at the end of input, "" if a new word starts
+func typedWord(input string) string {
+	_, _, words := typedWords(input)
+	return words[len(words)-1]
 }
 
-func (m *PromptModel) genHandlerSuggests(input string) {
-	m.matchSuggests = make([]Suggest, 0)
-	for handlerName, h := range m.handlerInfos {
+// resolveHandler returns the handler named by words, walking down the sub handlers along
+// them, and the index in words of its name. It is nil if words doesn't start with the
+// name of a handler.
+func (m *PromptModel) resolveHandler(words []string) (*HandlerInfo, int) {
+	if len(words) == 0 {
+		return nil, 0
+	}
+	handler, ok := m.handlerInfos[words[0]]
+	if !ok {
+		return nil, 0
+	}
+	depth := 0
+	for depth+1 < len(words) {
+		subHandler, ok := handler.SubHandlers[words[depth+1]]
+		if !ok {
+			break
+		}
+		handler = subHandler
+		depth++
+	}
+	return handler, depth
+}
+
+// genHandlerSuggests returns the handlers whose name matches input
+func genHandlerSuggests(input string, handlers map[string]*HandlerInfo) []Suggest {
+	matchSuggests := make([]Suggest, 0)
+	for handlerName, h := range handlers {
 		if IsMatch(input, handlerName) {
-			m.matchSuggests = append(m.matchSuggests, Suggest{
+			matchSuggests = append(matchSuggests, Suggest{
 				Text:        handlerName,
 				SuggestType: SuggestOfHandler,
 				Description: h.HelpMsg,
 			})
 		}
 	}
+	return matchSuggests
 }
 
 type RunCmdMsg struct {
 	cmd string
 }
 
+// runCmdDoneMsg is sent when all commands of a cmd line have finished or are canceled
+type runCmdDoneMsg struct {
+	result cmdResult
+}
+
 func runCmd(cmd string) tea.Cmd {
 	return func() tea.Msg {
 		return RunCmdMsg{
@@ -0,0 +1,127 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel returns a model that neither reads nor writes the history file of the
+// working dir
+func newTestModel(t *testing.T, opts ...PromptModelOption) *PromptModel {
+	opts = append([]PromptModelOption{WithHistoryFile(filepath.Join(t.TempDir(), "history")), WithOutSaveHistory()}, opts...)
+	return NewPromptModel(opts...)
+}
+
+func TestRunSubHandler(t *testing.T) {
+	ran := []string{}
+	m := newTestModel(t)
+	m.RegisterHandler(nil, "app", WithSubHandlers(
+		NewHandlerInfo("quit", func() { ran = append(ran, "quit") }, WithExitAfterRun(true)),
+		NewHandlerInfo("echo", func(text string) { ran = append(ran, "echo "+text) },
+			WithSuggests([]Suggest{{Text: "text", Positional: true}})),
+		NewHandlerInfo("boom", func() { panic("boom") }),
+	))
+	tests := []struct {
+		cmd     string
+		ran     []string
+		exit    bool
+		errLine string
+	}{
+		{"app echo hi", []string{"echo hi"}, false, ""},
+		{"app quit", []string{"quit"}, true, ""},
+		{"app echo a; app quit; app echo b", []string{"echo a", "quit"}, true, ""},
+		{"app boom", []string{}, false, "run cmd of handler[app boom] fail, err: boom"},
+		{"app", []string{}, false, "run cmd of handler[app] fail"},
+		{"nope", []string{}, false, "can't find handler[nope]"},
+	}
+	for _, tt := range tests {
+		ran = []string{}
+		result := m.runCmd(context.Background(), tt.cmd)
+		if strings.Join(ran, ",") != strings.Join(tt.ran, ",") || result.exit != tt.exit {
+			t.Errorf("runCmd(%q) ran %q, exit %v, want %q, exit %v", tt.cmd, ran, result.exit, tt.ran, tt.exit)
+		}
+		errLines := strings.Join(result.errLines, "\n")
+		if (tt.errLine == "") != (errLines == "") || !strings.Contains(errLines, tt.errLine) {
+			t.Errorf("runCmd(%q) errors %q, want %q", tt.cmd, errLines, tt.errLine)
+		}
+	}
+}
+
+func TestRunCmdCancel(t *testing.T) {
+	ran := []string{}
+	m := newTestModel(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	m.RegisterHandler(func() { ran = append(ran, "a"); cancel() }, "a")
+	m.RegisterHandler(func() { ran = append(ran, "b") }, "b")
+	// the rest of the chain doesn't start after cancel
+	m.runCmd(ctx, "a; b")
+	if len(ran) != 1 || ran[0] != "a" {
+		t.Errorf("runCmd ran %q after cancel, want [a]", ran)
+	}
+}
+
+func TestUpdateWhileRunning(t *testing.T) {
+	m := newTestModel(t)
+	canceled := false
+	m.runCmdMark, m.cancelRunCmd = true, func() { canceled = true }
+	// the line can't be edited while a cmd runs
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if value := m.textInput.Value(); value != "" {
+		t.Errorf("input = %q while running, want empty", value)
+	}
+	if canceled {
+		t.Errorf("a key canceled the running cmd")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !canceled {
+		t.Errorf("ctrl+c didn't cancel the running cmd")
+	}
+	// the prompt comes back when the cmd is done
+	m.Update(runCmdDoneMsg{result: cmdResult{err: context.Canceled}})
+	if m.runCmdMark || !errors.Is(m.LastError(), context.Canceled) {
+		t.Errorf("runCmdMark = %v, LastError() = %v after done", m.runCmdMark, m.LastError())
+	}
+}
+
+func TestExecStatus(t *testing.T) {
+	errBad := errors.New("bad")
+	ran := []string{}
+	m := newTestModel(t)
+	m.RegisterHandler(func() { ran = append(ran, "ok") }, "ok")
+	m.RegisterHandler(func() error { ran = append(ran, "fail"); return errBad }, "fail")
+	m.RegisterHandler(func() error { ran = append(ran, "nil"); return nil }, "nil")
+	tests := []struct {
+		cmd     string
+		ran     []string
+		wantErr bool
+	}{
+		{"ok", []string{"ok"}, false},
+		{"nil", []string{"nil"}, false},
+		{"fail", []string{"fail"}, true},
+		{"ok && fail", []string{"ok", "fail"}, true},
+		{"fail && ok", []string{"fail"}, true},
+		{"fail || ok", []string{"fail", "ok"}, false},
+		{"ok || fail", []string{"ok"}, false},
+		{"fail; ok", []string{"fail", "ok"}, false},
+		// a skipped command keeps the status of the one before
+		{"fail && ok || nil", []string{"fail", "nil"}, false},
+		{"ok || fail && nil", []string{"ok", "nil"}, false},
+		{"nope || ok", []string{"ok"}, false},
+	}
+	for _, tt := range tests {
+		ran = []string{}
+		err := m.Exec(tt.cmd)
+		if strings.Join(ran, ",") != strings.Join(tt.ran, ",") || (err != nil) != tt.wantErr {
+			t.Errorf("Exec(%q) ran %q, err %v, want %q, wantErr %v", tt.cmd, ran, err, tt.ran, tt.wantErr)
+		}
+		var handlerErr *HandlerError
+		if tt.wantErr && (!errors.As(err, &handlerErr) || handlerErr.Name != "fail" || !errors.Is(err, errBad)) {
+			t.Errorf("Exec(%q) err = %v, want the HandlerError of handler[fail]", tt.cmd, err)
+		}
+	}
+}
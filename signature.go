@@ -0,0 +1,47 @@
+package prompt
+
+import (
+	"strings"
+)
+
+// signatureView renders the usage line of the handler typed before the cursor, like
+// "calc [-a int16=10000] [-b int16]", with the param under the cursor highlighted and the
+// params already supplied dimmed. It is empty until the handler name is entered.
+func (m *PromptModel) signatureView() string {
+	if !m.signatureHelp {
+		return ""
+	}
+	value := m.textInput.Value()
+	cursor := len(string([]rune(value)[:min(m.textInput.Position(), len([]rune(value)))]))
+	_, _, cmds := typedWords(value[:cursor])
+	handler, depth := m.resolveHandler(cmds[:len(cmds)-1])
+	if handler == nil || handler.Handler == nil {
+		return ""
+	}
+	parts := handler.usageParts(true)
+	if len(parts) == 1 {
+		// nothing to describe
+		return ""
+	}
+	inputCtx := handler.analyzeInputs(cmds[depth:])
+	current := inputCtx.Suggest
+	switch {
+	case inputCtx.Kind == inputFlagName:
+		name, _, _ := flagName(inputCtx.Value)
+		current = handler.lookupSuggest(name)
+	case inputCtx.Kind == inputPositional && current < 0 && handler.numVariadic() > 0:
+		current = usageOfVariadic
+	}
+	views := []string{}
+	for _, part := range parts {
+		switch {
+		case part.Suggest == current && current != usageOfName:
+			views = append(views, m.signatureCurrentStyle.Render(part.Text))
+		case inputCtx.Supplied[part.Suggest]:
+			views = append(views, m.signatureSuppliedStyle.Render(part.Text))
+		default:
+			views = append(views, m.signatureStyle.Render(part.Text))
+		}
+	}
+	return strings.Join(views, m.signatureStyle.Render(" "))
+}
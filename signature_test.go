@@ -0,0 +1,28 @@
+package prompt
+
+import (
+	"testing"
+)
+
+func TestSignatureView(t *testing.T) {
+	m := newTestModel(t)
+	m.RegisterHandler(func(a, b int16) {}, "calc",
+		WithSuggests([]Suggest{{Text: "a", Default: int16(10000)}, {Text: "b", Required: true}}))
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"ca", ""},
+		{"calc", ""},
+		{"calc ", "calc [-a int16=10000] -b int16"},
+		{"calc -a 1 -b", "calc [-a int16=10000] -b int16"},
+		{"nope ", ""},
+	}
+	for _, tt := range tests {
+		m.textInput.SetValue(tt.line)
+		m.textInput.CursorEnd()
+		if got := m.signatureView(); got != tt.want {
+			t.Errorf("signatureView() of %q = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
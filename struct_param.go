@@ -0,0 +1,171 @@
+package prompt
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// struct tags of the fields of a struct param, e.g.
+//
+//	type DeployOptions struct {
+//		Env   string `flag:"env" short:"e" alias:"environment" default:"dev" desc:"target env"`
+//		Force bool   `desc:"deploy without check"`
+//		Dir   string `positional:"true" desc:"work dir"`
+//		Port  int    `required:"true" min:"1" max:"65535"`
+//		Mode  string `enum:"fast,safe" pattern:"[a-z]+"`
+//		Temp  string `flag:"-"` // not bound
+//	}
+const (
+	tagName       = "flag" // name of the flag, "-" skips the field, lower camel case field name by default
+	tagShort      = "short"
+	tagAlias      = "alias"   // comma separated other long names
+	tagDefault    = "default" // parsed by the converter of the field type
+	tagDesc       = "desc"
+	tagRequired   = "required" // a required field can't have a default, it is never used
+	tagPositional = "positional"
+	tagMin        = "min"     // number, see Suggest.Min
+	tagMax        = "max"     // number, see Suggest.Max
+	tagPattern    = "pattern" // regexp, see Suggest.Pattern
+	tagEnum       = "enum"    // comma separated allowed values
+)
+
+// structParam returns the struct type if the handler has a single param (between an
+// optional context.Context and an optional variadic param) which is a struct of
+// unregistered type. The fields of the
+// struct are then bound to the suggests instead of the params.
+func (h *HandlerInfo) structParam() (reflect.Type, bool) {
+	if h.HandlerReflecType == nil || h.HandlerReflecType.Kind() != reflect.Func || !h.UseFlagSet {
+		return nil, false
+	}
+	offset := h.paramOffset()
+	if h.HandlerReflecType.NumIn()-h.numVariadic() != offset+1 {
+		return nil, false
+	}
+	t := h.HandlerReflecType.In(offset)
+	if t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if _, ok := lookupConverter(t); ok {
+		return nil, false
+	}
+	return t, true
+}
+
+// structFields returns the fields of t bound to suggests
+func structFields(t reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get(tagName) == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// structSuggests build the suggests of the struct param t from its field tags
+func structSuggests(t reflect.Type) ([]Suggest, error) {
+	suggests := []Suggest{}
+	for _, field := range structFields(t) {
+		s := Suggest{
+			Text:        field.Tag.Get(tagName),
+			Short:       field.Tag.Get(tagShort),
+			Description: field.Tag.Get(tagDesc),
+		}
+		if s.Text == "" {
+			s.Text = strings.ToLower(field.Name[:1]) + field.Name[1:]
+		}
+		if aliases := field.Tag.Get(tagAlias); aliases != "" {
+			s.Aliases = strings.Split(aliases, ",")
+		}
+		if required, ok := field.Tag.Lookup(tagRequired); ok {
+			var err error
+			if s.Required, err = strconv.ParseBool(required); err != nil {
+				return nil, fmt.Errorf("field[%s] has invalid required tag[%s], err: %v", field.Name, required, err)
+			}
+		}
+		if positional, ok := field.Tag.Lookup(tagPositional); ok {
+			var err error
+			if s.Positional, err = strconv.ParseBool(positional); err != nil {
+				return nil, fmt.Errorf("field[%s] has invalid positional tag[%s], err: %v", field.Name, positional, err)
+			}
+		}
+		limits := []struct {
+			tag   string
+			value *interface{}
+		}{{tagMin, &s.Min}, {tagMax, &s.Max}}
+		for _, limit := range limits {
+			if text, ok := field.Tag.Lookup(limit.tag); ok {
+				number, err := strconv.ParseFloat(text, 64)
+				if err != nil {
+					return nil, fmt.Errorf("field[%s] has invalid %s tag[%s], err: %v", field.Name, limit.tag, text, err)
+				}
+				*limit.value = number
+			}
+		}
+		s.Pattern = field.Tag.Get(tagPattern)
+		if enum := field.Tag.Get(tagEnum); enum != "" {
+			s.Enum = strings.Split(enum, ",")
+		}
+		if defaultValue, ok := field.Tag.Lookup(tagDefault); ok {
+			converter, ok := lookupConverter(field.Type)
+			if !ok {
+				return nil, fmt.Errorf("field[%s] of type[%s] is not support, need register first",
+					field.Name, field.Type.String())
+			}
+			value, err := converter.Parse(defaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("field[%s] has invalid default tag[%s], err: %v", field.Name, defaultValue, err)
+			}
+			if s.Required {
+				return nil, fmt.Errorf("field[%s] is required and can't have a default tag", field.Name)
+			}
+			s.Default = value
+		}
+		suggests = append(suggests, s)
+	}
+	return suggests, nil
+}
+
+// paramTypes returns the types bound to the suggests, in order
+func (h *HandlerInfo) paramTypes() []reflect.Type {
+	types := []reflect.Type{}
+	if t, ok := h.structParam(); ok {
+		for _, field := range structFields(t) {
+			types = append(types, field.Type)
+		}
+		return types
+	}
+	for i := h.paramOffset(); i < h.HandlerReflecType.NumIn()-h.numVariadic(); i++ {
+		types = append(types, h.HandlerReflecType.In(i))
+	}
+	return types
+}
+
+// numVariadic returns 1 if the handler is variadic, the variadic param isn't bound to
+// a suggest
+func (h *HandlerInfo) numVariadic() int {
+	if _, ok := h.variadicElem(); ok {
+		return 1
+	}
+	return 0
+}
+
+// paramArgs convert the parsed params to the args of the handler, ctx excluded
+func (h *HandlerInfo) paramArgs() []reflect.Value {
+	if t, ok := h.structParam(); ok {
+		v := reflect.New(t).Elem()
+		for index, field := range structFields(t) {
+			v.FieldByIndex(field.Index).Set(convertParam(h.Params[index], field.Type))
+		}
+		return []reflect.Value{v}
+	}
+	args := []reflect.Value{}
+	for index, t := range h.paramTypes() {
+		args = append(args, convertParam(h.Params[index], t))
+	}
+	return args
+}
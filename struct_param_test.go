@@ -0,0 +1,48 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStructSuggests(t *testing.T) {
+	type options struct {
+		Env    string `flag:"env" short:"e" default:"dev" enum:"dev,prod"`
+		Port   int    `required:"true" min:"1"`
+		Dir    string `positional:"true"`
+		hidden string
+		Temp   string `flag:"-"`
+	}
+	suggests, err := structSuggests(reflect.TypeOf(options{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Suggest{
+		{Text: "env", Short: "e", Default: "dev", Enum: []string{"dev", "prod"}},
+		{Text: "port", Required: true, Min: 1.0},
+		{Text: "dir", Positional: true},
+	}
+	if !reflect.DeepEqual(suggests, want) {
+		t.Errorf("structSuggests = %+v, want %+v", suggests, want)
+	}
+
+	invalids := []interface{}{
+		struct {
+			Env string `default:"dev" required:"true"`
+		}{},
+		struct {
+			Port int `default:"x"`
+		}{},
+		struct {
+			Port int `required:"yes"`
+		}{},
+		struct {
+			Port int `min:"one"`
+		}{},
+	}
+	for _, v := range invalids {
+		if _, err := structSuggests(reflect.TypeOf(v)); err == nil {
+			t.Errorf("structSuggests(%T) err = nil, want err", v)
+		}
+	}
+}
@@ -1,12 +1,16 @@
 package prompt
 
 import (
+	"regexp"
 	"sort"
 )
 
 const (
 	SuggestOfParam = iota
 	SuggestOfHandler
+	SuggestOfValue      // value of a param, inserted quoted if needed
+	SuggestOfPositional // hint of the positional param under the cursor, not inserted
+	SuggestOfLoading    // shown while async suggests are loading, not inserted
 )
 
 type Suggest struct {
@@ -14,7 +18,36 @@ type Suggest struct {
 	Description string
 	Default     interface{}
 
+	Short      string   // short alias of the param, e.g. "v" for "verbose"
+	Aliases    []string // other long names of the param
+	Required   bool     // the param must be set in the cmd
+	Positional bool     // the param is filled by the args without flag in order, e.g. "cp src dst"
+
+	// validators checked before the handler is called, all violations are reported together
+	Min      interface{}               // min of a number param, a value of any number type
+	Max      interface{}               // max of a number param, a value of any number type
+	Pattern  string                    // regexp the whole formatted value must match
+	Enum     []string                  // allowed values, compared with the formatted value
+	Validate func(v interface{}) error // custom check of the parsed value
+
+	// ValueCompleter completes the value of the param while it is typed, input is the
+	// value typed so far. The Enum values are completed if it is nil.
+	ValueCompleter CompleteFunc
+	// Partial marks a completed value that isn't final, e.g. a dir of CompletePath. Tab on
+	// the only suggest accepts it and goes on completing behind it.
+	Partial bool
+
 	SuggestType int
+
+	score   int            // score of the typed word, see rankSuggests
+	rank    int            // bonus added to the score, e.g. by the recency of a history value
+	matched []int          // rune indexes of Text matched by the typed word, highlighted in the list
+	pattern *regexp.Regexp // Pattern compiled by checkValidators
+}
+
+// isHintSuggest reports whether s is only shown and not inserted by tab
+func isHintSuggest(s Suggest) bool {
+	return s.SuggestType == SuggestOfPositional || s.SuggestType == SuggestOfLoading
 }
 
 func SortSuggest(suggests []Suggest) []Suggest {
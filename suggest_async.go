@@ -0,0 +1,60 @@
+package prompt
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// AsyncGetSuggestFunc is like GetSuggestFunc but slow, e.g. it queries a database. It is
+// called outside of the ui goroutine after the input stays unchanged for the debounce
+// interval, ctx is canceled as soon as the input changes again.
+type AsyncGetSuggestFunc func(ctx context.Context, h *HandlerInfo, input string) ([]Suggest, error)
+
+// asyncSuggestMsg delivers the suggests of an async request to Update
+type asyncSuggestMsg struct {
+	seq      int          // the request, results of stale requests are dropped
+	handler  *HandlerInfo // the handler the suggests are for
+	suggests []Suggest    // suggests known before the request, e.g. sub handlers, included
+}
+
+// loadingSuggest is shown while an async request is running
+var loadingSuggest = Suggest{Text: "loading...", SuggestType: SuggestOfLoading}
+
+// requestSuggests cancels the running async request and starts a new one for input of h,
+// knownSuggests are shown with its results.
+func (m *PromptModel) requestSuggests(h *HandlerInfo, input string, knownSuggests []Suggest) tea.Cmd {
+	m.cancelSuggestRequest()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSuggest = cancel
+	seq, f, debounce := m.suggestSeq, h.AsyncGetSuggestMethod, h.SuggestDebounce
+	// the list shown meanwhile shares the array of knownSuggests, the cmd runs outside of
+	// Update and must not write to it
+	known := append([]Suggest(nil), knownSuggests...)
+	return func() tea.Msg {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(debounce):
+		}
+		suggests, err := f(ctx, h, input)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			suggests = []Suggest{}
+		}
+		all := make([]Suggest, 0, len(known)+len(suggests))
+		return asyncSuggestMsg{seq: seq, handler: h, suggests: append(append(all, known...), suggests...)}
+	}
+}
+
+// cancelSuggestRequest cancels the running async request if any, its results are dropped
+func (m *PromptModel) cancelSuggestRequest() {
+	m.suggestSeq++
+	if m.cancelSuggest != nil {
+		m.cancelSuggest()
+		m.cancelSuggest = nil
+	}
+}
@@ -0,0 +1,53 @@
+package prompt
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func suggestTexts(suggests []Suggest) []string {
+	texts := []string{}
+	for _, s := range suggests {
+		texts = append(texts, s.Text)
+	}
+	return texts
+}
+
+func TestRequestSuggests(t *testing.T) {
+	m := newTestModel(t)
+	remote := func(ctx context.Context, h *HandlerInfo, input string) ([]Suggest, error) {
+		return []Suggest{{Text: "remote"}}, nil
+	}
+	m.RegisterHandler(func() {}, "app", WithAsyncGetSuggestMethod(remote, 0), WithSubHandlers(
+		NewHandlerInfo("aa", func() {}), NewHandlerInfo("ab", func() {}), NewHandlerInfo("ac", func() {})))
+	m.textInput.SetValue("app ")
+	m.historyBuffers[m.historyIndex], m.historyBufferPos = "app ", 4
+	cmd := m.refreshSuggests()
+	loading := []string{"aa", "ab", "ac", "loading..."}
+	if got := suggestTexts(m.matchSuggests); !reflect.DeepEqual(got, loading) {
+		t.Fatalf("suggests = %q while loading, want %q", got, loading)
+	}
+	msg := cmd()
+	// the suggests only change in Update
+	if got := suggestTexts(m.matchSuggests); !reflect.DeepEqual(got, loading) {
+		t.Errorf("suggests = %q after the request, want %q until Update", got, loading)
+	}
+	m.Update(msg)
+	want := []string{"aa", "ab", "ac", "remote"}
+	if got := suggestTexts(m.matchSuggests); !reflect.DeepEqual(got, want) {
+		t.Errorf("suggests = %q after Update, want %q", got, want)
+	}
+
+	// the results of a request are dropped if the input changed meanwhile
+	m.textInput.SetValue("app a")
+	m.historyBuffers[m.historyIndex], m.historyBufferPos = "app a", 5
+	msg = m.refreshSuggests()()
+	m.textInput.SetValue("app ab")
+	m.historyBuffers[m.historyIndex], m.historyBufferPos = "app ab", 6
+	m.refreshSuggests()
+	m.Update(msg)
+	if got := suggestTexts(m.matchSuggests); !reflect.DeepEqual(got, []string{"ab", "loading..."}) {
+		t.Errorf("suggests = %q after a stale result, want [ab loading...]", got)
+	}
+}
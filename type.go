@@ -1,29 +1,379 @@
 package prompt
 
 import (
+	"encoding"
+	"flag"
+	"fmt"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var supportTypeMap = map[string]reflect.Type{}
+// ParseFunc parse the text typed by user into a value of the registered type
+type ParseFunc func(s string) (interface{}, error)
+
+// FormatFunc format a value of the registered type, it is used to show defaults
+type FormatFunc func(v interface{}) string
+
+// CompleteFunc returns the suggests for a param value, input is the part of the value
+// already typed
+type CompleteFunc func(input string) []Suggest
+
+// Converter converts between the text of a command line and a handler param type
+type Converter struct {
+	Type     reflect.Type
+	Parse    ParseFunc
+	Format   FormatFunc   // optional, fmt.Sprint by default
+	Complete CompleteFunc // optional, suggest values while typing
+
+	sliceOf   *Converter // element converter of a slice param, see sliceConverter
+	pointerTo *Converter // element converter of a pointer param, see pointerConverter
+}
+
+var converters = map[reflect.Type]*Converter{}
 
 func init() {
-	initSupportTypes := []interface{}{
-		"0",                                           // string
-		true,                                          // bool
-		int(0), int8(0), int16(0), int32(0), int64(0), // int
-		uint(0), uint8(0), uint16(0), uint32(0), uint64(0), // uint
-		float32(0), float64(0), // float
+	RegisterConverter("", Converter{Parse: func(s string) (interface{}, error) { return s, nil }})
+	RegisterConverter(true, Converter{Parse: func(s string) (interface{}, error) { return strconv.ParseBool(s) }})
+
+	intTypes := []interface{}{int(0), int8(0), int16(0), int32(0), int64(0)}
+	for _, i := range intTypes {
+		t := reflect.TypeOf(i)
+		RegisterConverter(i, Converter{Parse: func(s string) (interface{}, error) {
+			v, err := strconv.ParseInt(s, 0, t.Bits())
+			return reflect.ValueOf(v).Convert(t).Interface(), err
+		}})
+	}
+	uintTypes := []interface{}{uint(0), uint8(0), uint16(0), uint32(0), uint64(0)}
+	for _, i := range uintTypes {
+		t := reflect.TypeOf(i)
+		RegisterConverter(i, Converter{Parse: func(s string) (interface{}, error) {
+			v, err := strconv.ParseUint(s, 0, t.Bits())
+			return reflect.ValueOf(v).Convert(t).Interface(), err
+		}})
 	}
-	for _, initSupportType := range initSupportTypes {
-		RegisterType(initSupportType)
+	floatTypes := []interface{}{float32(0), float64(0)}
+	for _, i := range floatTypes {
+		t := reflect.TypeOf(i)
+		RegisterConverter(i, Converter{Parse: func(s string) (interface{}, error) {
+			v, err := strconv.ParseFloat(s, t.Bits())
+			return reflect.ValueOf(v).Convert(t).Interface(), err
+		}})
 	}
+
+	RegisterConverter(time.Duration(0), Converter{Parse: func(s string) (interface{}, error) { return time.ParseDuration(s) }})
+	RegisterConverter(url.URL{}, Converter{
+		Parse: func(s string) (interface{}, error) {
+			u, err := url.Parse(s)
+			if err != nil {
+				return url.URL{}, err
+			}
+			return *u, nil
+		},
+		Format: func(v interface{}) string {
+			u := v.(url.URL)
+			return u.String()
+		},
+	})
 }
 
+// RegisterType register the type of i as handler param type. A pointer to the type
+// must implement flag.Value or encoding.TextUnmarshaler, otherwise use RegisterConverter.
+// Types already supported, like int, time.Duration or []int, are kept as they are.
 func RegisterType(i interface{}) {
-	supportTypeMap[reflect.TypeOf(i).String()] = reflect.TypeOf(i)
+	t := reflect.TypeOf(i)
+	if _, ok := converters[t]; ok {
+		return
+	}
+	switch reflect.New(t).Interface().(type) {
+	case flag.Value:
+		RegisterConverter(i, Converter{
+			Parse: func(s string) (interface{}, error) {
+				p := reflect.New(t)
+				err := p.Interface().(flag.Value).Set(s)
+				return p.Elem().Interface(), err
+			},
+			Format: func(v interface{}) string {
+				p := reflect.New(t)
+				p.Elem().Set(reflect.ValueOf(v))
+				return p.Interface().(flag.Value).String()
+			},
+		})
+	case encoding.TextUnmarshaler:
+		RegisterConverter(i, Converter{
+			Parse: func(s string) (interface{}, error) {
+				p := reflect.New(t)
+				err := p.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+				return p.Elem().Interface(), err
+			},
+			Format: func(v interface{}) string {
+				p := reflect.New(t)
+				p.Elem().Set(reflect.ValueOf(v))
+				if m, ok := p.Interface().(encoding.TextMarshaler); ok {
+					if text, err := m.MarshalText(); err == nil {
+						return string(text)
+					}
+				}
+				return fmt.Sprint(v)
+			},
+		})
+	default:
+		if _, ok := lookupConverter(t); ok {
+			// a slice or pointer of a supported type
+			return
+		}
+		panic(fmt.Errorf("type[%s] implements neither flag.Value nor encoding.TextUnmarshaler, "+
+			"use RegisterConverter", t.String()))
+	}
+}
+
+// RegisterConverter register the type of i as handler param type with the converter c,
+// e.g. RegisterConverter(time.Duration(0), Converter{Parse: ...}). Registering a type
+// again replaces its converter.
+func RegisterConverter(i interface{}, c Converter) {
+	if c.Parse == nil {
+		panic(fmt.Errorf("converter of type[%T] has no parse func", i))
+	}
+	c.Type = reflect.TypeOf(i)
+	if c.Format == nil {
+		c.Format = func(v interface{}) string {
+			return fmt.Sprint(v)
+		}
+	}
+	converters[c.Type] = &c
+}
+
+// lookupConverter returns the converter of t. A slice type that isn't registered
+// itself is supported if its element type is registered.
+func lookupConverter(t reflect.Type) (*Converter, bool) {
+	if c, ok := converters[t]; ok {
+		return c, ok
+	}
+	if t.Kind() == reflect.Slice {
+		if elem, ok := converters[t.Elem()]; ok {
+			return sliceConverter(t, elem), true
+		}
+	}
+	if t.Kind() == reflect.Ptr {
+		if elem, ok := lookupConverter(t.Elem()); ok {
+			return pointerConverter(t, elem), true
+		}
+	}
+	return nil, false
+}
+
+// pointerConverter returns the converter of the pointer type t. A pointer param is an
+// optional param, it is nil if the flag is not set.
+func pointerConverter(t reflect.Type, elem *Converter) *Converter {
+	return &Converter{
+		Type: t,
+		Parse: func(s string) (interface{}, error) {
+			v, err := elem.Parse(s)
+			if err != nil {
+				return nil, err
+			}
+			p := reflect.New(t.Elem())
+			p.Elem().Set(reflect.ValueOf(v))
+			return p.Interface(), nil
+		},
+		Format: func(v interface{}) string {
+			if isNil(v) {
+				return ""
+			}
+			return elem.Format(reflect.ValueOf(v).Elem().Interface())
+		},
+		Complete:  elem.Complete,
+		pointerTo: elem,
+	}
+}
+
+// isBoolType reports whether t is bool or a pointer to bool, such a param is set by
+// "-name" without value
+func isBoolType(t reflect.Type) bool {
+	return t.Kind() == reflect.Bool || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Bool)
+}
+
+// sliceConverter returns the converter of the slice type t. The text of a slice is a
+// comma separated list of elements, a repeated flag appends to the slice.
+func sliceConverter(t reflect.Type, elem *Converter) *Converter {
+	return &Converter{
+		Type: t,
+		Parse: func(s string) (interface{}, error) {
+			slice := reflect.MakeSlice(t, 0, 0)
+			if strings.TrimSpace(s) == "" {
+				return slice.Interface(), nil
+			}
+			for _, item := range strings.Split(s, ",") {
+				v, err := elem.Parse(strings.TrimSpace(item))
+				if err != nil {
+					return nil, err
+				}
+				slice = reflect.Append(slice, reflect.ValueOf(v))
+			}
+			return slice.Interface(), nil
+		},
+		Format: func(v interface{}) string {
+			slice := reflect.ValueOf(v)
+			items := make([]string, slice.Len())
+			for index := range items {
+				items[index] = elem.Format(slice.Index(index).Interface())
+			}
+			return strings.Join(items, ",")
+		},
+		Complete: completeLastItem(elem.Complete),
+		sliceOf:  elem,
+	}
+}
+
+// completeLastItem returns the CompleteFunc of a comma separated list whose elements
+// are completed by complete, nil if complete is nil
+func completeLastItem(complete CompleteFunc) CompleteFunc {
+	if complete == nil {
+		return nil
+	}
+	return func(input string) []Suggest {
+		head := ""
+		if index := strings.LastIndex(input, ","); index >= 0 {
+			head, input = input[:index+1], input[index+1:]
+		}
+		suggests := complete(input)
+		for index := range suggests {
+			suggests[index].Text = head + suggests[index].Text
+		}
+		return suggests
+	}
+}
+
+// valueCompleter returns the func completing the value of the param of s whose type is
+// converted by c: the ValueCompleter of s, the Enum of s, the Complete of c, or true and
+// false for a bool param. Enum values are the elements of a slice param.
+func valueCompleter(s *Suggest, c *Converter) CompleteFunc {
+	switch {
+	case s.ValueCompleter != nil:
+		return s.ValueCompleter
+	case len(s.Enum) > 0:
+		if c.sliceOf != nil || (c.pointerTo != nil && c.pointerTo.sliceOf != nil) {
+			return completeLastItem(listCompleter(s.Enum))
+		}
+		return listCompleter(s.Enum)
+	case c.Complete != nil:
+		return c.Complete
+	case isBoolType(c.Type):
+		return listCompleter([]string{"true", "false"})
+	}
+	return nil
+}
+
+// listCompleter returns a CompleteFunc offering the values matching the input
+func listCompleter(values []string) CompleteFunc {
+	return func(input string) []Suggest {
+		suggests := []Suggest{}
+		for _, value := range values {
+			if IsMatch(input, value) {
+				suggests = append(suggests, Suggest{Text: value})
+			}
+		}
+		return suggests
+	}
+}
+
+// formatValue formats v with the converter of its type, slices are shown as [a, b] and
+// nil pointers as "unset"
+func formatValue(v interface{}) string {
+	if v == nil {
+		return fmt.Sprint(v)
+	}
+	c, ok := lookupConverter(reflect.TypeOf(v))
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	if c.pointerTo != nil {
+		if isNil(v) {
+			return "unset"
+		}
+		return formatValue(reflect.ValueOf(v).Elem().Interface())
+	}
+	if c.sliceOf != nil {
+		slice := reflect.ValueOf(v)
+		items := make([]string, slice.Len())
+		for index := range items {
+			items[index] = c.sliceOf.Format(slice.Index(index).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return c.Format(v)
+}
+
+// paramValue is the flag.Value of a handler param, it holds a value of the param type
+type paramValue struct {
+	converter *Converter
+	value     reflect.Value
+	set       bool  // Set was called, a slice param replaces its default on the first Set
+	err       error // parse error of Set, reported with the other violations by checkParams
+}
+
+func newParamValue(c *Converter, defaultValue interface{}) *paramValue {
+	return &paramValue{
+		converter: c,
+		value:     copyDefault(reflect.ValueOf(defaultValue)),
+	}
+}
+
+// copyDefault returns a copy of the pointee of a pointer default or of the elements of a
+// slice default, so a handler writing through its param can't change the default of the
+// next runs
+func copyDefault(v reflect.Value) reflect.Value {
+	if !v.IsValid() || isNil(v.Interface()) {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(v.Elem())
+		return p
+	case reflect.Slice:
+		return reflect.AppendSlice(reflect.MakeSlice(v.Type(), 0, v.Len()), v)
+	}
+	return v
+}
+
+func (p *paramValue) String() string {
+	// flag package calls String on a zero paramValue to check defaults
+	if p == nil || p.converter == nil {
+		return ""
+	}
+	return p.converter.Format(p.value.Interface())
+}
+
+func (p *paramValue) Set(s string) error {
+	v, err := p.converter.Parse(s)
+	if err != nil {
+		// keep parsing the other args, so all the invalid values are reported together
+		if p.err == nil {
+			p.err = parseError(p.converter, s, err)
+		}
+		p.set = true
+		return nil
+	}
+	value := reflect.ValueOf(v)
+	if !value.IsValid() || value.Type() != p.converter.Type {
+		return fmt.Errorf("converter of type[%s] return value of type[%T]", p.converter.Type.String(), v)
+	}
+	if p.converter.sliceOf != nil && p.set {
+		value = reflect.AppendSlice(p.value, value)
+	}
+	p.value = value
+	p.set = true
+	return nil
+}
+
+// IsBoolFlag let bool params be set by "-name" without value
+func (p *paramValue) IsBoolFlag() bool {
+	return isBoolType(p.converter.Type)
 }
 
-func isSupportType(name string) (reflect.Type, bool) {
-	t, ok := supportTypeMap[name]
-	return t, ok
+func (p *paramValue) Get() interface{} {
+	return p.value.Interface()
 }
@@ -34,33 +34,36 @@ func IsMatch(input, suggest string) bool {
 type GetSuggestFunc func(h *HandlerInfo, input string) ([]Suggest, error)
 
 func DefaultGetHandlerSuggests(h *HandlerInfo, input string) ([]Suggest, error) {
-	splitedInput := strings.Split(input, " ")
-	// filter extra spaces
-	inputs := []string{}
-	for _, s := range splitedInput {
-		if len(s) > 0 {
-			inputs = append(inputs, s)
-		}
+	// inputs的最后一个一定是当前在输入的值, 空字符串表示当前在等待输入一个新的参数
+	_, _, inputs := typedWords(input)
+	if len(inputs) < 2 {
+		return make([]Suggest, 0), nil
 	}
-
-	isInputLast := len(input) == 0 || input[len(input)-1] != ' '
-	if len(input) == 0 || !isInputLast {
-		inputs = append(inputs, "") // 添加空字符串表示当前在等待输入一个新的参数, inputs的最后一个一定是当前在输入的值
+	inputCtx := h.analyzeInputs(inputs)
+	switch inputCtx.Kind {
+	case inputFlagValue:
+		// 正在输入参数值, 此时只返回参数类型提供的值
+		return h.valueSuggests(inputCtx), nil
+	case inputPositional:
+		matchSuggests := h.positionalHint(inputCtx)
+		matchSuggests = append(matchSuggests, h.valueSuggests(inputCtx)...)
+		if inputCtx.Value != "" {
+			return matchSuggests, nil
+		}
+		return append(matchSuggests, h.flagSuggests(inputCtx.Value)...), nil
+	default:
+		return h.flagSuggests(inputCtx.Value), nil
 	}
+}
 
+// flagSuggests returns the flags whose name matches input
+func (h *HandlerInfo) flagSuggests(input string) []Suggest {
 	matchSuggests := make([]Suggest, 0)
-	// input custom param, not need suggest
-	notInputHandler := len(inputs) > 1
-	isInputParamValue := notInputHandler &&
-		(IsBoolSuggest(h.Suggests, inputs[len(inputs)-1], h.SuggestPrefix) ||
-			IsInputNotBoolValue(inputs, h.SuggestPrefix, h.Suggests))
-
-	// 正在输入参数值, 此时不反回suggest
-	if isInputParamValue {
-		return matchSuggests, nil
-	}
 	for _, s := range h.Suggests {
-		if IsMatch(inputs[len(inputs)-1], s.Text) {
+		if s.Positional {
+			continue
+		}
+		if IsMatch(input, s.Text) {
 			newSuggest := Suggest{
 				Text:        h.SuggestPrefix + s.Text,
 				Description: s.Description,
@@ -68,8 +71,54 @@ func DefaultGetHandlerSuggests(h *HandlerInfo, input string) ([]Suggest, error)
 			}
 			matchSuggests = append(matchSuggests, newSuggest)
 		}
+		// offer the short form too, -v for --verbose
+		if s.Short != "" && IsMatch(input, s.Short) {
+			shortPrefix := h.SuggestPrefix
+			if h.GNUFlags {
+				shortPrefix = gnuShortPrefix
+			}
+			description := fmt.Sprintf("short of %s%s", h.SuggestPrefix, s.Text)
+			if s.Description != "" {
+				description += ", " + s.Description
+			}
+			matchSuggests = append(matchSuggests, Suggest{
+				Text:        shortPrefix + s.Short,
+				Description: description,
+				Default:     s.Default,
+			})
+		}
+	}
+	return matchSuggests
+}
+
+// positionalHint returns a hint of the positional slot under the cursor, like
+// "<dst>: positional 2/2, destination"
+func (h *HandlerInfo) positionalHint(inputCtx inputContext) []Suggest {
+	if inputCtx.Suggest < 0 {
+		if _, ok := h.variadicElem(); !ok {
+			return []Suggest{}
+		}
+		description := fmt.Sprintf("variadic, arg %d", inputCtx.Slot-len(h.positionalSuggests())+1)
+		if h.Variadic.Description != "" {
+			description += ", " + h.Variadic.Description
+		}
+		return []Suggest{{
+			Text:        "<" + h.Variadic.Text + "...>",
+			Description: description,
+			SuggestType: SuggestOfPositional,
+		}}
+	}
+	s := h.Suggests[inputCtx.Suggest]
+	description := fmt.Sprintf("positional %d/%d", inputCtx.Slot+1, len(h.positionalSuggests()))
+	if s.Description != "" {
+		description += ", " + s.Description
 	}
-	return matchSuggests, nil
+	return []Suggest{{
+		Text:        "<" + s.Text + ">",
+		Description: description,
+		Default:     s.Default,
+		SuggestType: SuggestOfPositional,
+	}}
 }
 
 func min(a, b int) int {
@@ -86,7 +135,7 @@ func max(a, b int) int {
 	return b
 }
 
-const helpMsg = "ctrl+d: exit; tab, shift+tab choise suggest; ↑↓ choise history cmd"
+const helpMsg = "ctrl+d: exit; ctrl+c: clear line or cancel running cmd; tab, shift+tab choise suggest; ↑↓ choise history cmd; ctrl+r, ctrl+s: search history; →, ctrl+f, alt+f: accept hint"
 
 func HelpView() string {
 	return helpStyle(helpMsg)
@@ -269,8 +318,12 @@ func float64ToFloatx(src interface{}, dst reflect.Type) interface{} {
 	}
 }
 
+// convertParam convert the param value after parsing to the type of the handler param.
+// The int64, uint64 and float64 cases are for params of a custom FlagSetInitFunc.
 func convertParam(src interface{}, dst reflect.Type) reflect.Value {
 	switch src.(type) {
+	case *paramValue:
+		return src.(*paramValue).value
 	case *int64, int64:
 		return reflect.ValueOf(int64ToIntx(src, dst))
 	case *float64, float64:
@@ -0,0 +1,190 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ParamError is returned by Run if the params of the cmd are invalid or missing, it
+// holds all the violations instead of the first one
+type ParamError struct {
+	Name       string
+	Violations []string
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("handler[%s] invalid params: %s", e.Name, strings.Join(e.Violations, "; "))
+}
+
+// parseError describes the error of converter c parsing s
+func parseError(c *Converter, s string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("invalid value %q: out of range of %s", s, c.Type.String())
+	}
+	return fmt.Errorf("invalid value %q: %v", s, err)
+}
+
+// paramLabel returns the name of the param shown in messages, -name or <name>
+func (h *HandlerInfo) paramLabel(s Suggest) string {
+	if s.Positional {
+		return "<" + s.Text + ">"
+	}
+	return h.SuggestPrefix + s.Text
+}
+
+// checkParams returns the violations of the parsed params, invalid values, missing
+// required params and values rejected by the validators of the suggests
+func (h *HandlerInfo) checkParams() []string {
+	violations := []string{}
+	for index, s := range h.Suggests {
+		param, ok := h.Params[index].(*paramValue)
+		if !ok {
+			continue
+		}
+		label := h.paramLabel(s)
+		switch {
+		case param.err != nil:
+			violations = append(violations, fmt.Sprintf("%s: %v", label, param.err))
+		case !param.set:
+			// defaults are not validated, they are chosen by the developer
+			if s.Required {
+				violations = append(violations, fmt.Sprintf("%s: required", label))
+			}
+		default:
+			for _, violation := range s.validate(param.converter, param.Get()) {
+				violations = append(violations, fmt.Sprintf("%s: %s", label, violation))
+			}
+		}
+	}
+	return violations
+}
+
+// validate returns the violations of v against the validators of s. The range, pattern
+// and enum are checked on the element of a pointer and the elements of a slice.
+func (s *Suggest) validate(c *Converter, v interface{}) []string {
+	violations := []string{}
+	items, itemConverter := validationItems(c, reflect.ValueOf(v))
+	for _, item := range items {
+		violations = append(violations, s.validateItem(itemConverter, item.Interface())...)
+	}
+	if s.Validate != nil {
+		if err := s.Validate(v); err != nil {
+			violations = append(violations, err.Error())
+		}
+	}
+	return violations
+}
+
+// validationItems returns the values in v checked by the validators and their converter
+func validationItems(c *Converter, v reflect.Value) ([]reflect.Value, *Converter) {
+	switch {
+	case c.pointerTo != nil:
+		if v.IsNil() {
+			return nil, c.pointerTo
+		}
+		return validationItems(c.pointerTo, v.Elem())
+	case c.sliceOf != nil:
+		items := make([]reflect.Value, v.Len())
+		for index := range items {
+			items[index] = v.Index(index)
+		}
+		return items, c.sliceOf
+	default:
+		return []reflect.Value{v}, c
+	}
+}
+
+// validateItem checks the range, pattern and enum of s on a single value
+func (s *Suggest) validateItem(c *Converter, v interface{}) []string {
+	violations := []string{}
+	if number, ok := toFloat(v); ok {
+		if lower, ok := toFloat(s.Min); ok && number < lower {
+			violations = append(violations, fmt.Sprintf("%s is less than min %v", c.Format(v), s.Min))
+		}
+		if upper, ok := toFloat(s.Max); ok && number > upper {
+			violations = append(violations, fmt.Sprintf("%s is greater than max %v", c.Format(v), s.Max))
+		}
+	}
+	text := c.Format(v)
+	if s.Pattern != "" {
+		pattern := s.pattern
+		if pattern == nil {
+			// not checked by checkValidators
+			pattern, _ = compilePattern(s.Pattern)
+		}
+		if pattern != nil && !pattern.MatchString(text) {
+			violations = append(violations, fmt.Sprintf("%q doesn't match pattern %s", text, s.Pattern))
+		}
+	}
+	if len(s.Enum) > 0 && !containsString(s.Enum, text) {
+		violations = append(violations, fmt.Sprintf("%q is not one of [%s]", text, strings.Join(s.Enum, ", ")))
+	}
+	return violations
+}
+
+// checkValidators checks the validators of s are usable for the param type t, the
+// pattern is compiled once here
+func checkValidators(s *Suggest, t reflect.Type) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if s.Min != nil || s.Max != nil {
+		if _, ok := toFloat(reflect.Zero(t).Interface()); !ok {
+			return fmt.Errorf("min and max of param[%s] need a number type, but type is %s", s.Text, t.String())
+		}
+	}
+	lower, hasMin := toFloat(s.Min)
+	if s.Min != nil && !hasMin {
+		return fmt.Errorf("min of param[%s] is not a number: %v", s.Text, s.Min)
+	}
+	upper, hasMax := toFloat(s.Max)
+	if s.Max != nil && !hasMax {
+		return fmt.Errorf("max of param[%s] is not a number: %v", s.Text, s.Max)
+	}
+	if hasMin && hasMax && lower > upper {
+		return fmt.Errorf("min %v of param[%s] is greater than max %v", s.Min, s.Text, s.Max)
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("pattern of param[%s] is invalid, err: %v", s.Text, err)
+		}
+		s.pattern, _ = compilePattern(s.Pattern)
+	}
+	return nil
+}
+
+// compilePattern compiles pattern to match the whole value
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// toFloat returns v as float64 if v is a number
+func toFloat(v interface{}) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
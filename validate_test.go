@@ -0,0 +1,54 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckParams(t *testing.T) {
+	h := NewHandlerInfo("serve", func(port int, name, mode string, sizes []int, level int8) {},
+		WithSuggests([]Suggest{
+			{Text: "port", Required: true, Min: 1, Max: 65535},
+			{Text: "name", Pattern: "[a-z]+"},
+			{Text: "mode", Enum: []string{"fast", "slow"}},
+			{Text: "sizes", Max: 10},
+			{Text: "level", Min: -1},
+		}))
+	if err := h.CheckAndInitHandler(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"-port", "80"}, []string{}},
+		{[]string{}, []string{"-port: required"}},
+		// defaults are not validated
+		{[]string{"-port", "1", "-name", "ab", "-mode", "slow", "-sizes", "10"}, []string{}},
+		{[]string{"-port", "0"}, []string{"-port: 0 is less than min 1"}},
+		{[]string{"-port", "70000"}, []string{"-port: 70000 is greater than max 65535"}},
+		{[]string{"-port", "x"}, []string{`-port: invalid value "x": strconv.ParseInt: parsing "x": invalid syntax`}},
+		{[]string{"-port", "80", "-level", "200"}, []string{`-level: invalid value "200": out of range of int8`}},
+		{[]string{"-port", "80", "-name", "Ab"}, []string{`-name: "Ab" doesn't match pattern [a-z]+`}},
+		{[]string{"-port", "80", "-mode", "quick"}, []string{`-mode: "quick" is not one of [fast, slow]`}},
+		{[]string{"-port", "80", "-sizes", "3,11,12"}, []string{
+			"-sizes: 11 is greater than max 10", "-sizes: 12 is greater than max 10",
+		}},
+		{[]string{"-port", "0", "-mode", "x"}, []string{
+			"-port: 0 is less than min 1", `-mode: "x" is not one of [fast, slow]`,
+		}},
+	}
+	for _, tt := range tests {
+		if err := h.InitParamsAndFlagSet(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := h.parseArgs(tt.args); err != nil {
+			t.Errorf("parseArgs(%q) err = %v", tt.args, err)
+			continue
+		}
+		got := h.checkParams()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("checkParams(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}